@@ -0,0 +1,115 @@
+package bridge
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// IBCStandardBridgeABI is the ABI of the methods of the IBCStandardBridge contract whose calls are
+// turned into cosmos messages. Revision numbers and heights form ibc-go client heights, orders
+// use the ibc-go channel Order values (1 unordered, 2 ordered).
+const IBCStandardBridgeABI = `[
+	{
+		"type": "function",
+		"name": "transfer",
+		"stateMutability": "nonpayable",
+		"inputs": [
+			{"name": "sourcePort", "type": "string"},
+			{"name": "sourceChannel", "type": "string"},
+			{"name": "denom", "type": "string"},
+			{"name": "amount", "type": "uint256"},
+			{"name": "receiver", "type": "string"},
+			{"name": "timeoutRevisionNumber", "type": "uint64"},
+			{"name": "timeoutRevisionHeight", "type": "uint64"},
+			{"name": "timeoutTimestamp", "type": "uint64"},
+			{"name": "memo", "type": "string"}
+		],
+		"outputs": []
+	},
+	{
+		"type": "function",
+		"name": "sendPacket",
+		"stateMutability": "nonpayable",
+		"inputs": [
+			{"name": "sourcePort", "type": "string"},
+			{"name": "sourceChannel", "type": "string"},
+			{"name": "timeoutRevisionNumber", "type": "uint64"},
+			{"name": "timeoutRevisionHeight", "type": "uint64"},
+			{"name": "timeoutTimestamp", "type": "uint64"},
+			{"name": "data", "type": "bytes"}
+		],
+		"outputs": []
+	},
+	{
+		"type": "function",
+		"name": "channelOpenInit",
+		"stateMutability": "nonpayable",
+		"inputs": [
+			{"name": "portId", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "order", "type": "uint8"},
+			{"name": "connectionHops", "type": "string[]"},
+			{"name": "counterpartyPortId", "type": "string"}
+		],
+		"outputs": []
+	},
+	{
+		"type": "function",
+		"name": "channelOpenTry",
+		"stateMutability": "nonpayable",
+		"inputs": [
+			{"name": "portId", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "order", "type": "uint8"},
+			{"name": "connectionHops", "type": "string[]"},
+			{"name": "counterpartyPortId", "type": "string"},
+			{"name": "counterpartyChannelId", "type": "string"},
+			{"name": "counterpartyVersion", "type": "string"},
+			{"name": "proofInit", "type": "bytes"},
+			{"name": "proofRevisionNumber", "type": "uint64"},
+			{"name": "proofRevisionHeight", "type": "uint64"}
+		],
+		"outputs": []
+	},
+	{
+		"type": "function",
+		"name": "channelOpenAck",
+		"stateMutability": "nonpayable",
+		"inputs": [
+			{"name": "portId", "type": "string"},
+			{"name": "channelId", "type": "string"},
+			{"name": "counterpartyChannelId", "type": "string"},
+			{"name": "counterpartyVersion", "type": "string"},
+			{"name": "proofTry", "type": "bytes"},
+			{"name": "proofRevisionNumber", "type": "uint64"},
+			{"name": "proofRevisionHeight", "type": "uint64"}
+		],
+		"outputs": []
+	},
+	{
+		"type": "function",
+		"name": "channelOpenConfirm",
+		"stateMutability": "nonpayable",
+		"inputs": [
+			{"name": "portId", "type": "string"},
+			{"name": "channelId", "type": "string"},
+			{"name": "proofAck", "type": "bytes"},
+			{"name": "proofRevisionNumber", "type": "uint64"},
+			{"name": "proofRevisionHeight", "type": "uint64"}
+		],
+		"outputs": []
+	}
+]`
+
+// bridgeABI is the parsed IBCStandardBridgeABI.
+var bridgeABI = mustParseABI(IBCStandardBridgeABI)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+
+	return parsed
+}
@@ -0,0 +1,144 @@
+// Package bridge decodes transactions sent to the IBCStandardBridge contract into cosmos messages.
+package bridge
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// DefaultAddress is the predeploy address of the IBCStandardBridge.
+const DefaultAddress = "0x42000000000000000000000000000000000000E1"
+
+// ErrNotBridgeTx is returned when decoding a transaction that is not sent to the bridge.
+var ErrNotBridgeTx = errors.New("not an IBC bridge transaction")
+
+// Bridge recognizes transactions sent to the IBCStandardBridge contract(s) and decodes them.
+type Bridge struct {
+	addresses map[common.Address]struct{}
+}
+
+// NewBridge returns a Bridge for the contracts at the given hex addresses, DefaultAddress is used
+// if none are given.
+func NewBridge(addresses []string) (*Bridge, error) {
+	if len(addresses) == 0 {
+		addresses = []string{DefaultAddress}
+	}
+
+	b := &Bridge{addresses: make(map[common.Address]struct{}, len(addresses))}
+	for _, address := range addresses {
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid IBC bridge address %q", address)
+		}
+		b.addresses[common.HexToAddress(address)] = struct{}{}
+	}
+
+	return b, nil
+}
+
+// IsBridgeTx returns true if tx is sent to one of the bridge contracts, contract creations never
+// are.
+func (b *Bridge) IsBridgeTx(tx *types.Transaction) bool {
+	if tx.To() == nil {
+		return false
+	}
+
+	_, ok := b.addresses[*tx.To()]
+	return ok
+}
+
+// DecodeCall decodes and validates the bridge method call made by tx. Returns ErrNotBridgeTx if tx
+// isn't sent to the bridge and ErrInvalidCall if the call data is malformed or fails validation.
+func (b *Bridge) DecodeCall(tx *types.Transaction) (Call, error) {
+	if !b.IsBridgeTx(tx) {
+		return nil, ErrNotBridgeTx
+	}
+
+	data := tx.Data()
+	if len(data) < 4 {
+		return nil, fmt.Errorf("%w: call data too short", ErrInvalidCall)
+	}
+
+	method, err := bridgeABI.MethodById(data[:4])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCall, err)
+	}
+
+	call, err := newCall(method.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed %s arguments: %w", ErrInvalidCall, method.Name, err)
+	}
+	if err := method.Inputs.Copy(call, args); err != nil {
+		return nil, fmt.Errorf("%w: malformed %s arguments: %w", ErrInvalidCall, method.Name, err)
+	}
+
+	if err := call.ValidateBasic(); err != nil {
+		return nil, err
+	}
+
+	return call, nil
+}
+
+// DecodeMsg decodes the marshalled transaction data and returns the cosmos message executing its
+// bridge call on behalf of the transaction sender. Returns false if data is not a bridge
+// transaction. Bridge calls without a cosmos message fail with ErrUnsupportedCall, their
+// transactions are executed by geth alone.
+func (b *Bridge) DecodeMsg(data hexutil.Bytes) (sdk.Msg, bool, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(data); err != nil || !b.IsBridgeTx(tx) {
+		// geth rejects transactions that can't be decoded, nothing for us to do.
+		return nil, false, nil
+	}
+
+	call, err := b.DecodeCall(tx)
+	if err != nil {
+		return nil, true, err
+	}
+
+	sender, err := types.LatestSignerForChainID(tx.ChainId()).Sender(tx)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to recover IBC bridge transaction sender: %w", err)
+	}
+
+	msg, err := call.Msg(Signer(sender))
+	if err != nil {
+		return nil, true, err
+	}
+
+	return msg, true, nil
+}
+
+// Signer returns the cosmos address of an ethereum account.
+func Signer(address common.Address) string {
+	return sdk.AccAddress(address.Bytes()).String()
+}
+
+// newCall returns an empty Call for the bridge method with the given name.
+func newCall(method string) (Call, error) {
+	switch method {
+	case "transfer":
+		return &TransferCall{}, nil
+	case "sendPacket":
+		return &SendPacketCall{}, nil
+	case "channelOpenInit":
+		return &ChannelOpenInitCall{}, nil
+	case "channelOpenTry":
+		return &ChannelOpenTryCall{}, nil
+	case "channelOpenAck":
+		return &ChannelOpenAckCall{}, nil
+	case "channelOpenConfirm":
+		return &ChannelOpenConfirmCall{}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown method %s", ErrUnsupportedCall, method)
+	}
+}
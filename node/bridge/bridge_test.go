@@ -0,0 +1,139 @@
+package bridge
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+)
+
+func TestNewBridge(t *testing.T) {
+	custom := common.HexToAddress("0x01")
+
+	testCases := []struct {
+		name       string
+		addresses  []string
+		expBridged []common.Address
+		expErr     bool
+	}{
+		{"success: default address", nil, []common.Address{common.HexToAddress(DefaultAddress)}, false},
+		{"success: configured address", []string{custom.Hex()}, []common.Address{custom}, false},
+		{"failure: invalid address", []string{"0x01"}, nil, true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := NewBridge(tc.addresses)
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			for _, address := range tc.expBridged {
+				address := address
+				require.True(t, b.IsBridgeTx(types.NewTx(&types.DynamicFeeTx{To: &address})))
+			}
+			require.False(t, b.IsBridgeTx(types.NewTx(&types.DynamicFeeTx{})), "contract creation")
+		})
+	}
+}
+
+func TestDecodeMsg(t *testing.T) {
+	b, err := NewBridge(nil)
+	require.NoError(t, err)
+	bridgeAddress := common.HexToAddress(DefaultAddress)
+	other := common.HexToAddress("0x01")
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	chainID := big.NewInt(1)
+
+	pack := func(method string, args ...any) []byte {
+		data, err := bridgeABI.Pack(method, args...)
+		require.NoError(t, err)
+		return data
+	}
+	signed := func(to *common.Address, data []byte) hexutil.Bytes {
+		tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{ChainID: chainID, To: to, Data: data})
+		require.NoError(t, err)
+		bz, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		return bz
+	}
+	deposit := func(data []byte) hexutil.Bytes {
+		bz, err := types.NewTx(&types.DepositTx{From: sender, To: &bridgeAddress, Data: data}).MarshalBinary()
+		require.NoError(t, err)
+		return bz
+	}
+
+	transfer := pack("transfer", "transfer", "channel-0", "stake", big.NewInt(100), "cosmos1receiver", uint64(0), uint64(10), uint64(0), "")
+	chanOpenInit := pack("channelOpenInit", "transfer", "ics20-1", uint8(channeltypes.UNORDERED), []string{"connection-0"}, "transfer")
+
+	testCases := []struct {
+		name        string
+		data        hexutil.Bytes
+		expBridgeTx bool
+		expMsg      any
+		expErr      error
+	}{
+		{"success: transfer", signed(&bridgeAddress, transfer), true, &transfertypes.MsgTransfer{}, nil},
+		{"success: transfer deposit", deposit(transfer), true, &transfertypes.MsgTransfer{}, nil},
+		{"success: channel open init", signed(&bridgeAddress, chanOpenInit), true, &channeltypes.MsgChannelOpenInit{}, nil},
+		{"success: tx to another address", signed(&other, transfer), false, nil, nil},
+		{"success: contract creation", signed(nil, transfer), false, nil, nil},
+		{"success: malformed tx", hexutil.Bytes{0x01, 0x02}, false, nil, nil},
+		{"failure: no call data", signed(&bridgeAddress, nil), true, nil, ErrInvalidCall},
+		{"failure: unknown method", signed(&bridgeAddress, []byte{0xde, 0xad, 0xbe, 0xef}), true, nil, ErrInvalidCall},
+		{"failure: truncated arguments", signed(&bridgeAddress, transfer[:36]), true, nil, ErrInvalidCall},
+		{
+			"failure: invalid source channel",
+			signed(&bridgeAddress, pack("transfer", "transfer", "", "stake", big.NewInt(100), "cosmos1receiver", uint64(0), uint64(10), uint64(0), "")),
+			true, nil, ErrInvalidCall,
+		},
+		{
+			"failure: no timeout",
+			signed(&bridgeAddress, pack("transfer", "transfer", "channel-0", "stake", big.NewInt(100), "cosmos1receiver", uint64(0), uint64(0), uint64(0), "")),
+			true, nil, ErrInvalidCall,
+		},
+		{
+			"failure: send packet has no message",
+			signed(&bridgeAddress, pack("sendPacket", "transfer", "channel-0", uint64(0), uint64(10), uint64(0), []byte("data"))),
+			true, nil, ErrUnsupportedCall,
+		},
+		{
+			"failure: invalid send packet",
+			signed(&bridgeAddress, pack("sendPacket", "transfer", "channel-0", uint64(0), uint64(0), uint64(0), []byte("data"))),
+			true, nil, ErrInvalidCall,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			msg, isBridgeTx, err := b.DecodeMsg(tc.data)
+			require.Equal(t, tc.expBridgeTx, isBridgeTx)
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			if tc.expMsg == nil {
+				require.Nil(t, msg)
+				return
+			}
+			require.IsType(t, tc.expMsg, msg)
+			require.Equal(t, Signer(sender), msg.GetSigners()[0].String())
+		})
+	}
+}
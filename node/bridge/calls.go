@@ -0,0 +1,235 @@
+package bridge
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	sdkmath "cosmossdk.io/math"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	host "github.com/cosmos/ibc-go/v7/modules/core/24-host"
+)
+
+var (
+	// ErrInvalidCall is returned for bridge calls whose arguments fail validation.
+	ErrInvalidCall = errors.New("invalid IBC bridge call")
+	// ErrUnsupportedCall is returned for bridge calls that have no cosmos message. Their
+	// transactions are executed by geth alone.
+	ErrUnsupportedCall = errors.New("unsupported IBC bridge call")
+)
+
+// Call is a decoded call to one of the IBCStandardBridge methods.
+type Call interface {
+	// Method returns the name of the bridge method called.
+	Method() string
+	// ValidateBasic performs stateless validation of the call arguments.
+	ValidateBasic() error
+	// Msg returns the cosmos message executing the call on behalf of signer.
+	Msg(signer string) (sdk.Msg, error)
+}
+
+var (
+	_ Call = (*TransferCall)(nil)
+	_ Call = (*SendPacketCall)(nil)
+	_ Call = (*ChannelOpenInitCall)(nil)
+	_ Call = (*ChannelOpenTryCall)(nil)
+	_ Call = (*ChannelOpenAckCall)(nil)
+	_ Call = (*ChannelOpenConfirmCall)(nil)
+)
+
+// TransferCall is an ICS-20 token transfer.
+type TransferCall struct {
+	SourcePort            string   `abi:"sourcePort"`
+	SourceChannel         string   `abi:"sourceChannel"`
+	Denom                 string   `abi:"denom"`
+	Amount                *big.Int `abi:"amount"`
+	Receiver              string   `abi:"receiver"`
+	TimeoutRevisionNumber uint64   `abi:"timeoutRevisionNumber"`
+	TimeoutRevisionHeight uint64   `abi:"timeoutRevisionHeight"`
+	TimeoutTimestamp      uint64   `abi:"timeoutTimestamp"`
+	Memo                  string   `abi:"memo"`
+}
+
+func (*TransferCall) Method() string { return "transfer" }
+
+func (c *TransferCall) ValidateBasic() error {
+	if c.Amount == nil {
+		return fmt.Errorf("%w: transfer amount must be set", ErrInvalidCall)
+	}
+	if err := validateTimeout(c.TimeoutRevisionHeight, c.TimeoutTimestamp); err != nil {
+		return err
+	}
+
+	return validateMsg(c.Msg(placeholderSigner))
+}
+
+func (c *TransferCall) Msg(signer string) (sdk.Msg, error) {
+	timeoutHeight := clienttypes.NewHeight(c.TimeoutRevisionNumber, c.TimeoutRevisionHeight)
+	return transfertypes.NewMsgTransfer(c.SourcePort, c.SourceChannel, c.token(), signer, c.Receiver, timeoutHeight, c.TimeoutTimestamp, c.Memo), nil
+}
+
+// token builds the transferred coin without panicking on invalid denoms, which fail validation.
+func (c *TransferCall) token() sdk.Coin {
+	return sdk.Coin{Denom: c.Denom, Amount: sdkmath.NewIntFromBigInt(c.Amount)}
+}
+
+// SendPacketCall sends an arbitrary packet over a channel owned by the bridge.
+type SendPacketCall struct {
+	SourcePort            string `abi:"sourcePort"`
+	SourceChannel         string `abi:"sourceChannel"`
+	TimeoutRevisionNumber uint64 `abi:"timeoutRevisionNumber"`
+	TimeoutRevisionHeight uint64 `abi:"timeoutRevisionHeight"`
+	TimeoutTimestamp      uint64 `abi:"timeoutTimestamp"`
+	Data                  []byte `abi:"data"`
+}
+
+func (*SendPacketCall) Method() string { return "sendPacket" }
+
+func (c *SendPacketCall) ValidateBasic() error {
+	if err := validateSource(c.SourcePort, c.SourceChannel); err != nil {
+		return err
+	}
+	if len(c.Data) == 0 {
+		return fmt.Errorf("%w: packet data must be set", ErrInvalidCall)
+	}
+
+	return validateTimeout(c.TimeoutRevisionHeight, c.TimeoutTimestamp)
+}
+
+// Msg always fails: ibc-go v7 sends packets through the keeper of the owning module, there is no
+// message for it.
+func (c *SendPacketCall) Msg(string) (sdk.Msg, error) {
+	return nil, fmt.Errorf("%w: %s has no cosmos message", ErrUnsupportedCall, c.Method())
+}
+
+// ChannelOpenInitCall starts a channel handshake.
+type ChannelOpenInitCall struct {
+	PortID             string   `abi:"portId"`
+	Version            string   `abi:"version"`
+	Order              uint8    `abi:"order"`
+	ConnectionHops     []string `abi:"connectionHops"`
+	CounterpartyPortID string   `abi:"counterpartyPortId"`
+}
+
+func (*ChannelOpenInitCall) Method() string { return "channelOpenInit" }
+
+func (c *ChannelOpenInitCall) ValidateBasic() error {
+	return validateMsg(c.Msg(placeholderSigner))
+}
+
+func (c *ChannelOpenInitCall) Msg(signer string) (sdk.Msg, error) {
+	return channeltypes.NewMsgChannelOpenInit(c.PortID, c.Version, channeltypes.Order(c.Order), c.ConnectionHops, c.CounterpartyPortID, signer), nil
+}
+
+// ChannelOpenTryCall answers a channel handshake started by the counterparty.
+type ChannelOpenTryCall struct {
+	PortID                string   `abi:"portId"`
+	Version               string   `abi:"version"`
+	Order                 uint8    `abi:"order"`
+	ConnectionHops        []string `abi:"connectionHops"`
+	CounterpartyPortID    string   `abi:"counterpartyPortId"`
+	CounterpartyChannelID string   `abi:"counterpartyChannelId"`
+	CounterpartyVersion   string   `abi:"counterpartyVersion"`
+	ProofInit             []byte   `abi:"proofInit"`
+	ProofRevisionNumber   uint64   `abi:"proofRevisionNumber"`
+	ProofRevisionHeight   uint64   `abi:"proofRevisionHeight"`
+}
+
+func (*ChannelOpenTryCall) Method() string { return "channelOpenTry" }
+
+func (c *ChannelOpenTryCall) ValidateBasic() error {
+	return validateMsg(c.Msg(placeholderSigner))
+}
+
+func (c *ChannelOpenTryCall) Msg(signer string) (sdk.Msg, error) {
+	proofHeight := clienttypes.NewHeight(c.ProofRevisionNumber, c.ProofRevisionHeight)
+	return channeltypes.NewMsgChannelOpenTry(
+		c.PortID, c.Version, channeltypes.Order(c.Order), c.ConnectionHops,
+		c.CounterpartyPortID, c.CounterpartyChannelID, c.CounterpartyVersion,
+		c.ProofInit, proofHeight, signer,
+	), nil
+}
+
+// ChannelOpenAckCall acknowledges the counterparty's ChannelOpenTry.
+type ChannelOpenAckCall struct {
+	PortID                string `abi:"portId"`
+	ChannelID             string `abi:"channelId"`
+	CounterpartyChannelID string `abi:"counterpartyChannelId"`
+	CounterpartyVersion   string `abi:"counterpartyVersion"`
+	ProofTry              []byte `abi:"proofTry"`
+	ProofRevisionNumber   uint64 `abi:"proofRevisionNumber"`
+	ProofRevisionHeight   uint64 `abi:"proofRevisionHeight"`
+}
+
+func (*ChannelOpenAckCall) Method() string { return "channelOpenAck" }
+
+func (c *ChannelOpenAckCall) ValidateBasic() error {
+	return validateMsg(c.Msg(placeholderSigner))
+}
+
+func (c *ChannelOpenAckCall) Msg(signer string) (sdk.Msg, error) {
+	proofHeight := clienttypes.NewHeight(c.ProofRevisionNumber, c.ProofRevisionHeight)
+	return channeltypes.NewMsgChannelOpenAck(c.PortID, c.ChannelID, c.CounterpartyChannelID, c.CounterpartyVersion, c.ProofTry, proofHeight, signer), nil
+}
+
+// ChannelOpenConfirmCall completes a channel handshake on the ChannelOpenTry side.
+type ChannelOpenConfirmCall struct {
+	PortID              string `abi:"portId"`
+	ChannelID           string `abi:"channelId"`
+	ProofAck            []byte `abi:"proofAck"`
+	ProofRevisionNumber uint64 `abi:"proofRevisionNumber"`
+	ProofRevisionHeight uint64 `abi:"proofRevisionHeight"`
+}
+
+func (*ChannelOpenConfirmCall) Method() string { return "channelOpenConfirm" }
+
+func (c *ChannelOpenConfirmCall) ValidateBasic() error {
+	return validateMsg(c.Msg(placeholderSigner))
+}
+
+func (c *ChannelOpenConfirmCall) Msg(signer string) (sdk.Msg, error) {
+	proofHeight := clienttypes.NewHeight(c.ProofRevisionNumber, c.ProofRevisionHeight)
+	return channeltypes.NewMsgChannelOpenConfirm(c.PortID, c.ChannelID, c.ProofAck, proofHeight, signer), nil
+}
+
+// placeholderSigner is a valid signer address used to validate messages before the signer, the
+// sender of the bridge transaction, is known.
+var placeholderSigner = sdk.AccAddress(make([]byte, 20)).String()
+
+// validateMsg runs the ibc-go validation of a message.
+func validateMsg(msg sdk.Msg, err error) error {
+	if err != nil {
+		return err
+	}
+
+	if err := msg.ValidateBasic(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidCall, err)
+	}
+
+	return nil
+}
+
+func validateSource(port, channel string) error {
+	if err := host.PortIdentifierValidator(port); err != nil {
+		return fmt.Errorf("%w: invalid source port: %w", ErrInvalidCall, err)
+	}
+	if err := host.ChannelIdentifierValidator(channel); err != nil {
+		return fmt.Errorf("%w: invalid source channel: %w", ErrInvalidCall, err)
+	}
+
+	return nil
+}
+
+// validateTimeout requires one of the timeouts to be set, packets without one can never time out.
+func validateTimeout(timeoutHeight, timeoutTimestamp uint64) error {
+	if timeoutHeight == 0 && timeoutTimestamp == 0 {
+		return fmt.Errorf("%w: timeout height or timestamp must be set", ErrInvalidCall)
+	}
+
+	return nil
+}
@@ -0,0 +1,321 @@
+// Package mempool implements the bounded, prioritized mempool holding the cosmos messages the
+// interceptor forwards to peptide.
+package mempool
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
+)
+
+const (
+	// DefaultMaxMsgs is the default maximum number of messages in the mempool.
+	DefaultMaxMsgs = 5000
+	// DefaultMaxBytes is the default maximum total size of the messages in the mempool.
+	DefaultMaxBytes = 64 << 20
+	// DefaultTTL is the default time a message is kept in the mempool.
+	DefaultTTL = 10 * time.Minute
+	// MaxRecentFailures is the number of dropped messages reported by Status.
+	MaxRecentFailures = 100
+)
+
+var (
+	// ErrDuplicateMsg is returned when adding a message that is already in the mempool.
+	ErrDuplicateMsg = errors.New("message already in mempool")
+	// ErrMempoolFull is returned when there is no room for a message, not even by evicting
+	// messages of lower priority.
+	ErrMempoolFull = errors.New("mempool is full")
+	// ErrMsgTooLarge is returned when a message alone exceeds the byte limit of the mempool.
+	ErrMsgTooLarge = errors.New("message exceeds the mempool size limit")
+)
+
+// Config holds the limits of the mempool, zero values select the defaults.
+type Config struct {
+	MaxMsgs  int
+	MaxBytes int
+	TTL      time.Duration
+}
+
+// Entry is a pending message along with its priority.
+type Entry struct {
+	*eetypes.MsgEnvelope
+	Priority Priority `json:"priority"`
+}
+
+// size returns the number of bytes accounted for the entry.
+func (e *Entry) size() int {
+	return len(e.TypeURL) + len(e.Value)
+}
+
+// Failure is a message dropped after it was taken from the mempool, because peptide rejected it
+// or it could not be requeued.
+type Failure struct {
+	*eetypes.MsgEnvelope
+	Reason string    `json:"reason"`
+	Time   time.Time `json:"time"`
+}
+
+// Status is a snapshot of the mempool.
+type Status struct {
+	Size     int `json:"size"`
+	Bytes    int `json:"bytes"`
+	MaxMsgs  int `json:"maxMsgs"`
+	MaxBytes int `json:"maxBytes"`
+	// Pending are the messages in the order they are forwarded in.
+	Pending []Entry `json:"pending"`
+	// RecentFailures are the last MaxRecentFailures messages dropped, oldest first.
+	RecentFailures []Failure `json:"recentFailures"`
+}
+
+// Mempool is a bounded set of messages, deduplicated by their id. Messages are handed out by
+// descending priority and in arrival order within a priority. Messages older than the TTL are
+// evicted, when full, lower priority messages make room for higher priority ones.
+type Mempool struct {
+	maxMsgs  int
+	maxBytes int
+	ttl      time.Duration
+	metrics  *Metrics
+	// now returns the current time, replaced in tests.
+	now func() time.Time
+
+	mtx      sync.Mutex
+	entries  map[common.Hash]*Entry
+	bytes    int
+	failures []Failure
+}
+
+// NewMempool returns an empty mempool with the given limits.
+func NewMempool(config Config, metrics *Metrics) *Mempool {
+	if config.MaxMsgs <= 0 {
+		config.MaxMsgs = DefaultMaxMsgs
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = DefaultMaxBytes
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultTTL
+	}
+
+	return &Mempool{
+		maxMsgs:  config.MaxMsgs,
+		maxBytes: config.MaxBytes,
+		ttl:      config.TTL,
+		metrics:  metrics,
+		now:      time.Now,
+		entries:  make(map[common.Hash]*Entry),
+	}
+}
+
+// Add adds a message to the mempool. Returns ErrDuplicateMsg if it is already pending and
+// ErrMempoolFull if no room can be made for it.
+func (m *Mempool) Add(msg *eetypes.MsgEnvelope) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.expire()
+	if _, ok := m.entries[msg.ID]; ok {
+		m.metrics.RejectedMsgs.Add(1)
+		return ErrDuplicateMsg
+	}
+
+	return m.add(&Entry{MsgEnvelope: msg, Priority: PriorityOf(msg.TypeURL)})
+}
+
+// Has returns true if there are pending messages.
+func (m *Mempool) Has() bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.expire()
+	return len(m.entries) > 0
+}
+
+// Msgs returns the pending messages in forwarding order, leaving them in place.
+func (m *Mempool) Msgs() []*eetypes.MsgEnvelope {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.expire()
+	return envelopes(m.sorted())
+}
+
+// Take removes and returns all pending messages in forwarding order.
+func (m *Mempool) Take() []*eetypes.MsgEnvelope {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.expire()
+	msgs := envelopes(m.sorted())
+	m.entries = make(map[common.Hash]*Entry)
+	m.bytes = 0
+	m.updateMetrics()
+
+	return msgs
+}
+
+// Requeue adds taken messages back, they keep their arrival time and thus their place. Messages
+// that expired or that no longer fit are dropped and returned.
+func (m *Mempool) Requeue(msgs []*eetypes.MsgEnvelope) []*eetypes.MsgEnvelope {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	var dropped []*eetypes.MsgEnvelope
+	for _, msg := range msgs {
+		if _, ok := m.entries[msg.ID]; ok {
+			continue
+		}
+		if m.expired(msg) {
+			m.metrics.EvictedMsgs.With("reason", "expired").Add(1)
+			m.fail(msg, "expired before it could be requeued")
+			dropped = append(dropped, msg)
+			continue
+		}
+		if err := m.add(&Entry{MsgEnvelope: msg, Priority: PriorityOf(msg.TypeURL)}); err != nil {
+			m.fail(msg, fmt.Sprintf("failed to requeue: %v", err))
+			dropped = append(dropped, msg)
+		}
+	}
+
+	return dropped
+}
+
+// ReportFailure records that a taken message was dropped for the given reason, e.g. because
+// peptide rejected it. Messages dropped by Requeue are recorded already.
+func (m *Mempool) ReportFailure(msg *eetypes.MsgEnvelope, reason error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.fail(msg, reason.Error())
+}
+
+// Status returns a snapshot of the mempool.
+func (m *Mempool) Status() Status {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.expire()
+	sorted := m.sorted()
+	pending := make([]Entry, len(sorted))
+	for i, entry := range sorted {
+		pending[i] = *entry
+	}
+
+	return Status{
+		Size:           len(m.entries),
+		Bytes:          m.bytes,
+		MaxMsgs:        m.maxMsgs,
+		MaxBytes:       m.maxBytes,
+		Pending:        pending,
+		RecentFailures: append([]Failure(nil), m.failures...),
+	}
+}
+
+// add inserts the entry, evicting lower priority entries if the mempool is full. Must be called
+// with mtx held.
+func (m *Mempool) add(entry *Entry) error {
+	size := entry.size()
+	if size > m.maxBytes {
+		m.metrics.RejectedMsgs.Add(1)
+		return fmt.Errorf("%w: %d > %d bytes", ErrMsgTooLarge, size, m.maxBytes)
+	}
+
+	// Find the entries to evict, lowest priority and most recent first, before evicting any so a
+	// failed add leaves the mempool untouched.
+	var evict []*Entry
+	count, bytes := len(m.entries), m.bytes
+	if count >= m.maxMsgs || bytes+size > m.maxBytes {
+		sorted := m.sorted()
+		for i := len(sorted) - 1; i >= 0 && (count >= m.maxMsgs || bytes+size > m.maxBytes); i-- {
+			if sorted[i].Priority >= entry.Priority {
+				m.metrics.RejectedMsgs.Add(1)
+				return ErrMempoolFull
+			}
+			evict = append(evict, sorted[i])
+			count--
+			bytes -= sorted[i].size()
+		}
+	}
+
+	for _, evicted := range evict {
+		m.remove(evicted)
+		m.metrics.EvictedMsgs.With("reason", "full").Add(1)
+	}
+
+	m.entries[entry.ID] = entry
+	m.bytes += size
+	m.updateMetrics()
+
+	return nil
+}
+
+// fail records the failure of a dropped message, keeping the last MaxRecentFailures. Must be
+// called with mtx held.
+func (m *Mempool) fail(msg *eetypes.MsgEnvelope, reason string) {
+	m.metrics.FailedMsgs.Add(1)
+	m.failures = append(m.failures, Failure{MsgEnvelope: msg, Reason: reason, Time: m.now()})
+	if len(m.failures) > MaxRecentFailures {
+		m.failures = m.failures[len(m.failures)-MaxRecentFailures:]
+	}
+}
+
+// expire evicts the messages older than the TTL. Must be called with mtx held.
+func (m *Mempool) expire() {
+	for _, entry := range m.entries {
+		if m.expired(entry.MsgEnvelope) {
+			m.remove(entry)
+			m.metrics.EvictedMsgs.With("reason", "expired").Add(1)
+		}
+	}
+	m.updateMetrics()
+}
+
+func (m *Mempool) expired(msg *eetypes.MsgEnvelope) bool {
+	return m.now().Sub(msg.ArrivalTime) > m.ttl
+}
+
+// remove deletes the entry. Must be called with mtx held.
+func (m *Mempool) remove(entry *Entry) {
+	delete(m.entries, entry.ID)
+	m.bytes -= entry.size()
+}
+
+// sorted returns the entries in forwarding order: by descending priority, then by arrival time
+// and id for a deterministic order. Must be called with mtx held.
+func (m *Mempool) sorted() []*Entry {
+	sorted := make([]*Entry, 0, len(m.entries))
+	for _, entry := range m.entries {
+		sorted = append(sorted, entry)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if !a.ArrivalTime.Equal(b.ArrivalTime) {
+			return a.ArrivalTime.Before(b.ArrivalTime)
+		}
+		return a.ID.Cmp(b.ID) < 0
+	})
+
+	return sorted
+}
+
+func (m *Mempool) updateMetrics() {
+	m.metrics.Size.Set(float64(len(m.entries)))
+	m.metrics.SizeBytes.Set(float64(m.bytes))
+}
+
+func envelopes(entries []*Entry) []*eetypes.MsgEnvelope {
+	msgs := make([]*eetypes.MsgEnvelope, len(entries))
+	for i, entry := range entries {
+		msgs[i] = entry.MsgEnvelope
+	}
+	return msgs
+}
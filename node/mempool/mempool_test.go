@@ -0,0 +1,223 @@
+package mempool
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+
+	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
+)
+
+var (
+	signer = sdk.AccAddress(make([]byte, 20)).String()
+	start  = time.Unix(1_700_000_000, 0)
+)
+
+func newEnvelope(t *testing.T, msg sdk.Msg, arrival time.Duration) *eetypes.MsgEnvelope {
+	t.Helper()
+
+	envelope, err := eetypes.NewMsgEnvelope(msg, start.Add(arrival))
+	require.NoError(t, err)
+	return envelope
+}
+
+func updateClient(t *testing.T, clientID string, arrival time.Duration) *eetypes.MsgEnvelope {
+	return newEnvelope(t, &clienttypes.MsgUpdateClient{ClientId: clientID, Signer: signer}, arrival)
+}
+
+func recvPacket(t *testing.T, sequence uint64, arrival time.Duration) *eetypes.MsgEnvelope {
+	packet := channeltypes.NewPacket([]byte("data"), sequence, "transfer", "channel-0", "transfer", "channel-1", clienttypes.NewHeight(0, 100), 0)
+	return newEnvelope(t, channeltypes.NewMsgRecvPacket(packet, []byte("proof"), clienttypes.NewHeight(0, 1), signer), arrival)
+}
+
+func transfer(t *testing.T, amount int64, arrival time.Duration) *eetypes.MsgEnvelope {
+	token := sdk.NewInt64Coin("stake", amount)
+	return newEnvelope(t, transfertypes.NewMsgTransfer("transfer", "channel-0", token, signer, "receiver", clienttypes.NewHeight(0, 100), 0, ""), arrival)
+}
+
+// newTestMempool returns a mempool whose clock is at now.
+func newTestMempool(config Config, now *time.Time) *Mempool {
+	m := NewMempool(config, NopMetrics())
+	m.now = func() time.Time { return *now }
+	return m
+}
+
+func TestMempoolOrder(t *testing.T) {
+	now := start
+	m := newTestMempool(Config{}, &now)
+
+	// Added in reverse priority order.
+	msgs := []*eetypes.MsgEnvelope{
+		transfer(t, 1, 0),
+		recvPacket(t, 2, 2*time.Second),
+		recvPacket(t, 1, time.Second),
+		updateClient(t, "07-tendermint-0", 3*time.Second),
+	}
+	for _, msg := range msgs {
+		require.NoError(t, m.Add(msg))
+	}
+
+	// Client updates first, then packets in arrival order, then transfers.
+	expected := []*eetypes.MsgEnvelope{msgs[3], msgs[2], msgs[1], msgs[0]}
+	require.Equal(t, expected, m.Msgs())
+	require.Equal(t, expected, m.Take())
+	require.False(t, m.Has())
+	require.Empty(t, m.Take())
+}
+
+func TestMempoolAdd(t *testing.T) {
+	now := start
+	first, second := recvPacket(t, 1, 0), recvPacket(t, 2, time.Second)
+	size := len(first.TypeURL) + len(first.Value)
+
+	testCases := []struct {
+		name    string
+		config  Config
+		pending []*eetypes.MsgEnvelope
+		msg     *eetypes.MsgEnvelope
+		expErr  error
+		expMsgs []*eetypes.MsgEnvelope
+	}{
+		{
+			"success",
+			Config{},
+			[]*eetypes.MsgEnvelope{first},
+			second,
+			nil,
+			[]*eetypes.MsgEnvelope{first, second},
+		},
+		{
+			"success: evicts the most recent lower priority message when full",
+			Config{MaxMsgs: 2},
+			[]*eetypes.MsgEnvelope{transfer(t, 1, 0), transfer(t, 2, time.Second)},
+			first,
+			nil,
+			[]*eetypes.MsgEnvelope{first, transfer(t, 1, 0)},
+		},
+		{
+			"success: evicts lower priority messages to fit the bytes",
+			Config{MaxBytes: size + 1},
+			[]*eetypes.MsgEnvelope{transfer(t, 1, 0)},
+			first,
+			nil,
+			[]*eetypes.MsgEnvelope{first},
+		},
+		{
+			"failure: duplicate",
+			Config{},
+			[]*eetypes.MsgEnvelope{first},
+			recvPacket(t, 1, time.Second),
+			ErrDuplicateMsg,
+			[]*eetypes.MsgEnvelope{first},
+		},
+		{
+			"failure: full of messages with the same priority",
+			Config{MaxMsgs: 1},
+			[]*eetypes.MsgEnvelope{first},
+			second,
+			ErrMempoolFull,
+			[]*eetypes.MsgEnvelope{first},
+		},
+		{
+			"failure: message too large",
+			Config{MaxBytes: size - 1},
+			nil,
+			first,
+			ErrMsgTooLarge,
+			[]*eetypes.MsgEnvelope{},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestMempool(tc.config, &now)
+			for _, msg := range tc.pending {
+				require.NoError(t, m.Add(msg))
+			}
+
+			err := m.Add(tc.msg)
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expMsgs, m.Msgs())
+		})
+	}
+}
+
+func TestMempoolExpiry(t *testing.T) {
+	now := start
+	m := newTestMempool(Config{TTL: time.Minute}, &now)
+
+	old, recent := recvPacket(t, 1, 0), recvPacket(t, 2, 30*time.Second)
+	require.NoError(t, m.Add(old))
+	require.NoError(t, m.Add(recent))
+
+	now = start.Add(time.Minute + time.Second)
+	require.Equal(t, []*eetypes.MsgEnvelope{recent}, m.Msgs())
+	require.Equal(t, 1, m.Status().Size)
+
+	// Requeued messages keep their arrival time, expired ones are dropped.
+	taken := m.Take()
+	now = start.Add(2 * time.Minute)
+	require.Equal(t, taken, m.Requeue(taken))
+	require.False(t, m.Has())
+}
+
+func TestMempoolRequeue(t *testing.T) {
+	now := start
+	m := newTestMempool(Config{MaxMsgs: 2}, &now)
+
+	first, second := recvPacket(t, 1, 0), recvPacket(t, 2, time.Second)
+	require.NoError(t, m.Add(first))
+	require.NoError(t, m.Add(second))
+	taken := m.Take()
+
+	// A message arriving while the taken ones were forwarded is ordered after them.
+	later := recvPacket(t, 3, 2*time.Second)
+	require.NoError(t, m.Add(later))
+
+	dropped := m.Requeue(taken)
+	require.Equal(t, []*eetypes.MsgEnvelope{second}, dropped)
+	require.Equal(t, []*eetypes.MsgEnvelope{first, later}, m.Msgs())
+
+	status := m.Status()
+	require.Equal(t, 2, status.Size)
+	require.Equal(t, 2, status.MaxMsgs)
+	require.Len(t, status.Pending, 2)
+	require.Equal(t, PriorityPacket, status.Pending[0].Priority)
+	require.Equal(t, first.ID, status.Pending[0].ID)
+
+	// The message that no longer fits is reported as failed.
+	require.Len(t, status.RecentFailures, 1)
+	require.Equal(t, second, status.RecentFailures[0].MsgEnvelope)
+	require.Contains(t, status.RecentFailures[0].Reason, ErrMempoolFull.Error())
+}
+
+func TestMempoolReportFailure(t *testing.T) {
+	now := start
+	m := newTestMempool(Config{}, &now)
+
+	for i := 0; i < MaxRecentFailures+1; i++ {
+		now = now.Add(time.Second)
+		m.ReportFailure(recvPacket(t, uint64(i), 0), errors.New("rejected"))
+	}
+
+	// Only the most recent failures are kept, oldest first.
+	failures := m.Status().RecentFailures
+	require.Len(t, failures, MaxRecentFailures)
+	require.Equal(t, recvPacket(t, 1, 0).ID, failures[0].ID)
+	require.Equal(t, recvPacket(t, MaxRecentFailures, 0).ID, failures[len(failures)-1].ID)
+	require.Equal(t, "rejected", failures[0].Reason)
+	require.Equal(t, start.Add(2*time.Second), failures[0].Time)
+}
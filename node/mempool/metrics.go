@@ -0,0 +1,73 @@
+package mempool
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsSubsystem is the subsystem shared by all metrics exposed by this package.
+const MetricsSubsystem = "mempool"
+
+// Metrics contains the metrics exposed by the mempool.
+type Metrics struct {
+	// Number of messages in the mempool.
+	Size metrics.Gauge
+	// Total size of the messages in the mempool in bytes.
+	SizeBytes metrics.Gauge
+	// Number of messages evicted from the mempool, labeled by the reason: "expired" or "full".
+	EvictedMsgs metrics.Counter
+	// Number of messages rejected because the mempool is full or they are duplicates.
+	RejectedMsgs metrics.Counter
+	// Number of messages dropped after being taken, because peptide rejected them or they could
+	// not be requeued.
+	FailedMsgs metrics.Counter
+}
+
+// PrometheusMetrics returns Metrics registered with the default prometheus registry.
+func PrometheusMetrics(namespace string) *Metrics {
+	return &Metrics{
+		Size: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "size",
+			Help:      "Number of messages in the mempool.",
+		}, nil),
+		SizeBytes: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "size_bytes",
+			Help:      "Total size of the messages in the mempool in bytes.",
+		}, nil),
+		EvictedMsgs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "evicted_msgs",
+			Help:      "Number of messages evicted from the mempool.",
+		}, []string{"reason"}),
+		RejectedMsgs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "rejected_msgs",
+			Help:      "Number of messages rejected by the mempool.",
+		}, nil),
+		FailedMsgs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "failed_msgs",
+			Help:      "Number of messages dropped after being forwarded to peptide.",
+		}, nil),
+	}
+}
+
+// NopMetrics returns Metrics that discard all observations.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		Size:         discard.NewGauge(),
+		SizeBytes:    discard.NewGauge(),
+		EvictedMsgs:  discard.NewCounter(),
+		RejectedMsgs: discard.NewCounter(),
+		FailedMsgs:   discard.NewCounter(),
+	}
+}
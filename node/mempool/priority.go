@@ -0,0 +1,61 @@
+package mempool
+
+import (
+	"github.com/cosmos/gogoproto/proto"
+
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	connectiontypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
+	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+)
+
+// Priority orders the messages in the mempool, higher priorities are forwarded first.
+type Priority int
+
+// Relayed messages depend on the client state of the counterparty being up to date and on the
+// connection and channel they use being open, so client updates go first, followed by the
+// handshakes, packet relays and finally new transfers.
+const (
+	PriorityDefault Priority = iota
+	PriorityTransfer
+	PriorityPacket
+	PriorityChannel
+	PriorityConnection
+	PriorityClient
+)
+
+// priorities maps message type urls to their priority, unknown types get PriorityDefault.
+var priorities = map[string]Priority{
+	typeURL(&clienttypes.MsgCreateClient{}):       PriorityClient,
+	typeURL(&clienttypes.MsgUpdateClient{}):       PriorityClient,
+	typeURL(&clienttypes.MsgUpgradeClient{}):      PriorityClient,
+	typeURL(&clienttypes.MsgSubmitMisbehaviour{}): PriorityClient, //nolint:staticcheck // still handled by ibc-go v7.
+
+	typeURL(&connectiontypes.MsgConnectionOpenInit{}):    PriorityConnection,
+	typeURL(&connectiontypes.MsgConnectionOpenTry{}):     PriorityConnection,
+	typeURL(&connectiontypes.MsgConnectionOpenAck{}):     PriorityConnection,
+	typeURL(&connectiontypes.MsgConnectionOpenConfirm{}): PriorityConnection,
+
+	typeURL(&channeltypes.MsgChannelOpenInit{}):     PriorityChannel,
+	typeURL(&channeltypes.MsgChannelOpenTry{}):      PriorityChannel,
+	typeURL(&channeltypes.MsgChannelOpenAck{}):      PriorityChannel,
+	typeURL(&channeltypes.MsgChannelOpenConfirm{}):  PriorityChannel,
+	typeURL(&channeltypes.MsgChannelCloseInit{}):    PriorityChannel,
+	typeURL(&channeltypes.MsgChannelCloseConfirm{}): PriorityChannel,
+
+	typeURL(&channeltypes.MsgRecvPacket{}):      PriorityPacket,
+	typeURL(&channeltypes.MsgAcknowledgement{}): PriorityPacket,
+	typeURL(&channeltypes.MsgTimeout{}):         PriorityPacket,
+	typeURL(&channeltypes.MsgTimeoutOnClose{}):  PriorityPacket,
+
+	typeURL(&transfertypes.MsgTransfer{}): PriorityTransfer,
+}
+
+// PriorityOf returns the priority of messages of the given type url.
+func PriorityOf(typeURL string) Priority {
+	return priorities[typeURL]
+}
+
+func typeURL(msg proto.Message) string {
+	return "/" + proto.MessageName(msg)
+}
@@ -1,20 +1,30 @@
 package node
 
 import (
-	"sync"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/ethereum-optimism/optimism/op-service/client"
 	"github.com/ethereum-optimism/optimism/op-service/eth"
 
+	"github.com/ibc-scouts/ibc-interceptor/node/bridge"
 	nodeclient "github.com/ibc-scouts/ibc-interceptor/node/client"
+	"github.com/ibc-scouts/ibc-interceptor/node/mempool"
 	"github.com/ibc-scouts/ibc-interceptor/node/server"
 	"github.com/ibc-scouts/ibc-interceptor/node/server/api"
+	"github.com/ibc-scouts/ibc-interceptor/node/store"
 	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
 	"github.com/ibc-scouts/ibc-interceptor/types"
 )
 
+// metricsNamespace is the prometheus namespace of the interceptor metrics.
+const metricsNamespace = "interceptor"
+
 // InterceptorNode is the main struct for the Interceptor node that facilitates communication
 // between the op-node on one side and the ethereum and sdk engines on the other. It holds
 // rpc clients for boths and intercepts all engine API calls performed by op-node.
@@ -26,14 +36,21 @@ type InterceptorNode struct {
 	// peptideRPC is the RPC client for the Peptide node
 	peptideRPC client.RPC
 
-	// msgMempool is a basic Mempool to be used in OpApp.
-	// TODO(jim): Might need to make into a full fledged type to support more complex mempool operations.
-	msgMempool   [][]byte
-	blockStore   map[common.Hash]eetypes.CompositeBlock
-	payloadStore map[eth.PayloadID]eetypes.CompositePayload
+	// mempool holds the cosmos messages to forward to peptide when building blocks.
+	mempool *mempool.Mempool
+	// blockStore persists composite blocks across restarts.
+	blockStore *store.BlockStore
+	// payloadStore holds the composite payloads of in flight block building jobs.
+	payloadStore *store.PayloadStore
+	// retainBlocks is the number of composite blocks kept below the finalized head.
+	retainBlocks uint64
+	// hashScheme computes the hashes of new composite blocks.
+	hashScheme eetypes.HashScheme
+
+	// metricsServer serves the prometheus metrics, nil if disabled.
+	metricsServer *http.Server
 
 	logger types.CompositeLogger
-	lock   sync.RWMutex
 }
 
 func NewInterceptorNode(config *types.Config) *InterceptorNode {
@@ -53,21 +70,67 @@ func NewInterceptorNode(config *types.Config) *InterceptorNode {
 		panic(err)
 	}
 
+	metrics, mempoolMetrics, engineMetrics := store.NopMetrics(), mempool.NopMetrics(), api.NopMetrics()
+	var metricsServer *http.Server
+	if config.MetricsAddr != "" {
+		metrics, mempoolMetrics = store.PrometheusMetrics(metricsNamespace), mempool.PrometheusMetrics(metricsNamespace)
+		engineMetrics = api.PrometheusMetrics(metricsNamespace)
+		metricsServer = &http.Server{
+			Addr:              config.MetricsAddr,
+			Handler:           promhttp.Handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+	}
+
+	// open the block store, in memory unless a data directory is configured.
+	blockStore, err := store.OpenBlockStore(config.DBBackend, config.DataDir, metrics)
+	if err != nil {
+		panic(err)
+	}
+
+	hashScheme, err := eetypes.ParseHashScheme(config.CompositeHashScheme)
+	if err != nil {
+		panic(err)
+	}
+
+	// decode the calls made to the IBC bridge contracts.
+	ibcBridge, err := bridge.NewBridge(config.IBCBridgeAddresses)
+	if err != nil {
+		panic(err)
+	}
+
 	node := &InterceptorNode{
-		logger:       logger,
-		ethRPC:       ethRPC,
-		peptideRPC:   peptideRPC,
-		blockStore:   make(map[common.Hash]eetypes.CompositeBlock),
-		payloadStore: make(map[eth.PayloadID]eetypes.CompositePayload),
+		logger:     logger,
+		ethRPC:     ethRPC,
+		peptideRPC: peptideRPC,
+		mempool: mempool.NewMempool(mempool.Config{
+			MaxMsgs:  config.MempoolMaxMsgs,
+			MaxBytes: config.MempoolMaxBytes,
+			TTL:      time.Duration(config.MempoolTTL),
+		}, mempoolMetrics),
+		blockStore:    blockStore,
+		payloadStore:  store.NewPayloadStore(time.Duration(config.PayloadTTL), metrics),
+		retainBlocks:  config.RetainBlocks,
+		hashScheme:    hashScheme,
+		metricsServer: metricsServer,
+	}
+
+	// bound the calls forwarded to the engines by each served method.
+	timeouts := api.Timeouts{
+		Default: time.Duration(config.RPCTimeout),
+		Methods: make(map[string]time.Duration, len(config.RPCMethodTimeouts)),
+	}
+	for method, timeout := range config.RPCMethodTimeouts {
+		timeouts.Methods[method] = time.Duration(timeout)
 	}
 
 	// Add APIs to the RPC server
-	rpcAPIs := api.GetEngineAPI(node, ethRPC, peptideRPC, logger.With("server", "exec_engine_api"))
+	rpcAPIs := api.GetEngineAPI(node, ibcBridge, ethRPC, peptideRPC, engineMetrics, timeouts, logger.With("server", "exec_engine_api"))
 	rpcAPIs = append(
 		rpcAPIs,
 		// Add eth and cosmos APIs
-		api.GetEthAPI(node, ethRPC, peptideRPC, logger.With("server", "eth_api")),
-		api.GetCosmosAPI(node, peptideRPC, logger.With("server", "cosmos_api")),
+		api.GetEthAPI(node, ethRPC, peptideRPC, timeouts, logger.With("server", "eth_api")),
+		api.GetCosmosAPI(node, peptideRPC, timeouts, logger.With("server", "cosmos_api")),
 	)
 
 	// Create config for the RPC server (address to bind to)
@@ -82,6 +145,14 @@ func (n *InterceptorNode) Start() error {
 		return err
 	}
 
+	if n.metricsServer != nil {
+		go func() {
+			if err := n.metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				n.logger.Error("metrics server failed", "error", err)
+			}
+		}()
+	}
+
 	return nil
 }
 
@@ -90,65 +161,153 @@ func (n *InterceptorNode) Stop() error {
 		return err
 	}
 
+	if n.metricsServer != nil {
+		if err := n.metricsServer.Close(); err != nil {
+			return err
+		}
+	}
+
 	n.ethRPC.Close()
 	n.peptideRPC.Close()
 
-	return nil
+	return n.blockStore.Close()
 }
 
 // -- MempoolNode interface --
 
-// AddTxToMempool add a tx to the mempool.
-func (n *InterceptorNode) AddMsgToMempool(bz []byte) {
-	n.lock.Lock()
-	defer n.lock.Unlock()
-
-	n.logger.Info("AddMsgToMempool", "msg", bz)
-	n.msgMempool = append(n.msgMempool, bz)
+// AddMsgToMempool adds a message to the mempool.
+func (n *InterceptorNode) AddMsgToMempool(msg *eetypes.MsgEnvelope) error {
+	n.logger.Info("AddMsgToMempool", "id", msg.ID, "type", msg.TypeURL, "sender", msg.Sender)
+	return n.mempool.Add(msg)
 }
 
 // HasMsgs returns true if the mempool has messages.
 func (n *InterceptorNode) HasMsgs() bool {
-	n.lock.RLock()
-	defer n.lock.RUnlock()
+	return n.mempool.Has()
+}
 
-	return len(n.msgMempool) > 0
+// GetMsgs returns all messages in the mempool in forwarding order, leaving them in place.
+func (n *InterceptorNode) GetMsgs() []*eetypes.MsgEnvelope {
+	return n.mempool.Msgs()
 }
 
-// GetMsgs returns all messages in the mempool.
-func (n *InterceptorNode) GetMsgs() [][]byte {
-	n.lock.Lock()
-	defer n.lock.Unlock()
+// TakeMsgs removes and returns all messages in the mempool in forwarding order.
+func (n *InterceptorNode) TakeMsgs() []*eetypes.MsgEnvelope {
+	return n.mempool.Take()
+}
 
-	return n.msgMempool
+// RequeueMsgs puts taken messages back in the mempool, returns those that no longer fit.
+func (n *InterceptorNode) RequeueMsgs(msgs []*eetypes.MsgEnvelope) []*eetypes.MsgEnvelope {
+	n.logger.Info("RequeueMsgs", "count", len(msgs))
+	return n.mempool.Requeue(msgs)
 }
 
-// ClearMsgs clears all messages from the mempool.
-func (n *InterceptorNode) ClearMsgs() {
-	n.lock.Lock()
-	defer n.lock.Unlock()
+// ReportFailedMsg records a message dropped because peptide rejected it.
+func (n *InterceptorNode) ReportFailedMsg(msg *eetypes.MsgEnvelope, reason error) {
+	n.mempool.ReportFailure(msg, reason)
+}
 
-	n.msgMempool = nil
+// MempoolStatus returns a snapshot of the mempool.
+func (n *InterceptorNode) MempoolStatus() mempool.Status {
+	return n.mempool.Status()
 }
 
 // -- BlockStore interface --
 
 // GetCompositeBlock returns a composite block given the combined block hash
-func (n *InterceptorNode) GetCompositeBlock(blockHash common.Hash) eetypes.CompositeBlock {
-	return n.blockStore[blockHash]
+func (n *InterceptorNode) GetCompositeBlock(blockHash common.Hash) (eetypes.CompositeBlock, error) {
+	return n.blockStore.Get(blockHash)
+}
+
+// GetCompositeBlockByGethHash returns the composite block containing the given geth block.
+func (n *InterceptorNode) GetCompositeBlockByGethHash(gethHash common.Hash) (eetypes.CompositeBlock, error) {
+	return n.blockStore.GetByGethHash(gethHash)
 }
 
-func (n *InterceptorNode) SaveCompositeBlock(compositeBlock eetypes.CompositeBlock) {
-	n.blockStore[compositeBlock.Hash()] = compositeBlock
+// GetCompositeBlockByABCIHash returns the composite block containing the given abci block.
+func (n *InterceptorNode) GetCompositeBlockByABCIHash(abciHash common.Hash) (eetypes.CompositeBlock, error) {
+	return n.blockStore.GetByABCIHash(abciHash)
+}
+
+// GetCompositeBlockByNumber returns the composite block at the given height.
+func (n *InterceptorNode) GetCompositeBlockByNumber(number uint64) (eetypes.CompositeBlock, error) {
+	return n.blockStore.GetByNumber(number)
+}
+
+// CompositeBlockOf returns the stored composite block of the given geth and abci blocks, which
+// may use a previous hash scheme, or a new one hashed with the configured scheme.
+func (n *InterceptorNode) CompositeBlockOf(gethHash, abciHash common.Hash) (eetypes.CompositeBlock, error) {
+	compositeBlock, err := n.blockStore.GetByGethHash(gethHash)
+	if err == nil && compositeBlock.ABCIHash == abciHash {
+		return compositeBlock, nil
+	} else if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return eetypes.CompositeBlock{}, err
+	}
+
+	return eetypes.NewCompositeBlockWithScheme(gethHash, abciHash, n.hashScheme), nil
+}
+
+// SaveCompositeBlock persists a composite block under its combined block hash.
+func (n *InterceptorNode) SaveCompositeBlock(compositeBlock eetypes.CompositeBlock) error {
+	return n.blockStore.Save(compositeBlock)
+}
+
+// SaveCommittedForkchoiceState persists the composite fork choice state both engines accepted.
+func (n *InterceptorNode) SaveCommittedForkchoiceState(fcs eth.ForkchoiceState) error {
+	return n.blockStore.SaveForkchoiceState(fcs)
+}
+
+// CommittedForkchoiceState returns the last committed composite fork choice state.
+func (n *InterceptorNode) CommittedForkchoiceState() (eth.ForkchoiceState, error) {
+	return n.blockStore.ForkchoiceState()
+}
+
+// SaveCompositeBlockWithNumber persists a composite block and indexes it by its height.
+func (n *InterceptorNode) SaveCompositeBlockWithNumber(compositeBlock eetypes.CompositeBlock, number uint64) error {
+	return n.blockStore.SaveWithNumber(compositeBlock, number)
+}
+
+// PruneCompositeBlocks removes the composite blocks more than retainBlocks below the given
+// finalized composite block. Does nothing if pruning is disabled or the finalized block's
+// height is unknown.
+func (n *InterceptorNode) PruneCompositeBlocks(finalized common.Hash) error {
+	if n.retainBlocks == 0 {
+		return nil
+	}
+
+	number, err := n.blockStore.NumberOf(finalized)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if number <= n.retainBlocks {
+		return nil
+	}
+
+	pruned, err := n.blockStore.PruneBelow(number - n.retainBlocks)
+	if err != nil {
+		return err
+	}
+	if pruned > 0 {
+		n.logger.Debug("pruned composite blocks", "pruned", pruned, "finalized", number, "size", n.blockStore.Size())
+	}
+
+	return nil
 }
 
 // -- PayloadStore interface --
 
 // GetCompositePayload returns a composite payload given the combined payload hash
-func (n *InterceptorNode) GetCompositePayload(compositePayload eth.PayloadID) eetypes.CompositePayload {
-	return n.payloadStore[compositePayload]
+func (n *InterceptorNode) GetCompositePayload(compositePayload eth.PayloadID) (eetypes.CompositePayload, error) {
+	return n.payloadStore.Get(compositePayload)
 }
 
 func (n *InterceptorNode) SaveCompositePayload(compositePayload eetypes.CompositePayload) {
-	n.payloadStore[*compositePayload.Payload()] = compositePayload
+	n.payloadStore.Save(compositePayload)
+}
+
+// DeleteCompositePayload removes a composite payload once it has been served.
+func (n *InterceptorNode) DeleteCompositePayload(compositePayload eth.PayloadID) {
+	n.payloadStore.Delete(compositePayload)
 }
@@ -0,0 +1,53 @@
+package node
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/ibc-scouts/ibc-interceptor/node/store"
+	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
+	"github.com/ibc-scouts/ibc-interceptor/types"
+)
+
+func TestPruneCompositeBlocks(t *testing.T) {
+	var blocks []eetypes.CompositeBlock
+	for i := uint64(0); i < 10; i++ {
+		blocks = append(blocks, eetypes.NewCompositeBlock(common.BigToHash(new(big.Int).SetUint64(i+1)), common.BigToHash(new(big.Int).SetUint64(i+100))))
+	}
+
+	testCases := []struct {
+		name         string
+		retainBlocks uint64
+		finalized    common.Hash
+		expSize      int
+	}{
+		{"retention disabled", 0, blocks[8].Hash(), 10},
+		{"unknown finalized block", 2, common.HexToHash("0xff"), 10},
+		{"finalized within the window", 8, blocks[5].Hash(), 10},
+		{"prune below the window", 2, blocks[8].Hash(), 4},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			blockStore, err := store.OpenBlockStore("", "", store.NopMetrics())
+			require.NoError(t, err)
+			t.Cleanup(func() { require.NoError(t, blockStore.Close()) })
+			for i, block := range blocks {
+				require.NoError(t, blockStore.SaveWithNumber(block, uint64(i)))
+			}
+
+			logger, err := types.NewCompositeLogger("error")
+			require.NoError(t, err)
+			node := &InterceptorNode{blockStore: blockStore, retainBlocks: tc.retainBlocks, logger: logger}
+			require.NoError(t, node.PruneCompositeBlocks(tc.finalized))
+			require.Equal(t, tc.expSize, blockStore.Size())
+			_, err = blockStore.Get(blocks[len(blocks)-1].Hash())
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	ibctypes "github.com/cosmos/ibc-go/v7/modules/core/types"
+	solomachine "github.com/cosmos/ibc-go/v7/modules/light-clients/06-solomachine"
+	ibctm "github.com/cosmos/ibc-go/v7/modules/light-clients/07-tendermint"
+)
+
+// ibcInterfaceRegistry resolves the Anys held by the IBC messages queued through the cosmos
+// namespace. ibc-go validates client states, consensus states and client messages through their
+// cached values, so messages are unpacked with it before ValidateBasic.
+var ibcInterfaceRegistry = newIBCInterfaceRegistry()
+
+// newIBCInterfaceRegistry returns an interface registry with the ibc-go core, ICS-20 and light
+// client types registered.
+func newIBCInterfaceRegistry() codectypes.InterfaceRegistry {
+	registry := codectypes.NewInterfaceRegistry()
+	ibctypes.RegisterInterfaces(registry)
+	transfertypes.RegisterInterfaces(registry)
+	solomachine.RegisterInterfaces(registry)
+	ibctm.RegisterInterfaces(registry)
+
+	return registry
+}
@@ -8,7 +8,18 @@ package api
 
 import (
 	"context"
-
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/ethereum-optimism/optimism/op-service/client"
@@ -16,19 +27,42 @@ import (
 
 	"github.com/cometbft/cometbft/libs/log"
 
+	"github.com/ibc-scouts/ibc-interceptor/node/bridge"
+	"github.com/ibc-scouts/ibc-interceptor/node/mempool"
+	"github.com/ibc-scouts/ibc-interceptor/node/store"
 	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
 )
 
 // TODO(jim): passed by lock.
-func GetEngineAPI(interceptor Interceptor, ethPRC, peptideRPC client.RPC, logger log.Logger) []rpc.API {
+func GetEngineAPI(
+	interceptor Interceptor,
+	ibcBridge *bridge.Bridge,
+	ethPRC, peptideRPC client.RPC,
+	metrics *Metrics,
+	timeouts Timeouts,
+	logger log.Logger,
+) []rpc.API {
 	return []rpc.API{
 		{
 			Namespace: "engine",
-			Service:   newEngineAPI(interceptor, ethPRC, peptideRPC, logger),
+			Service:   newEngineAPI(interceptor, ibcBridge, ethPRC, peptideRPC, metrics, timeouts, logger),
 		},
 	}
 }
 
+const (
+	// methodNotFoundCode is the JSON-RPC error code returned for unknown methods.
+	methodNotFoundCode = -32601
+
+	// peptideAddMsgMethod adds a message envelope to the peptide mempool. Each call is bounded by
+	// the timeout configured for it rather than by the one of the fork choice update.
+	peptideAddMsgMethod = "intercept_addMsgToTxMempool"
+)
+
+// errNoCommittedState is returned when geth must be rolled back before both engines ever
+// accepted a fork choice update.
+var errNoCommittedState = errors.New("no committed fork choice state to roll geth engine back to")
+
 /* 'engine_' prefixed server methods, only required ones. */
 
 // engineServer is the API for the execution engine.
@@ -37,144 +71,884 @@ func GetEngineAPI(interceptor Interceptor, ethPRC, peptideRPC client.RPC, logger
 type engineServer struct {
 	// mempoolNode contains a reference to the mempool.
 	interceptor Interceptor
+	// ibcBridge decodes the IBC bridge transactions among the forced transactions and the
+	// transactions of the payloads.
+	ibcBridge *bridge.Bridge
 	// ethRPC is an RPC client for calling into op-geth RPC server.
 	ethRPC client.RPC
 	// peptideRPC is an RPC client for calling into the peptide RPC server (sdk engine).
 	peptideRPC client.RPC
+	// metrics records the latency of the calls to both engines.
+	metrics *Metrics
+	// timeouts bound the calls forwarded to the engines by each method.
+	timeouts Timeouts
+
+	// fcuLock serializes the fork choice updates, so that a rollback restores the committed fork
+	// choice state of the last update.
+	fcuLock sync.Mutex
+	// depositsParent is the head the last payload was built on and forwardedDeposits are the
+	// sources of the IBC bridge deposits forwarded for it. The payload attributes of a block may be
+	// sent more than once, its deposits must only be executed once.
+	depositsParent    common.Hash
+	forwardedDeposits map[common.Hash]struct{}
 
 	logger log.Logger
 }
 
 // newExecutionEngineAPI returns a new execEngineAPI.
-func newEngineAPI(interceptor Interceptor, ethRPC, peptideRPC client.RPC, logger log.Logger) *engineServer {
-	return &engineServer{interceptor, ethRPC, peptideRPC, logger}
+func newEngineAPI(
+	interceptor Interceptor,
+	ibcBridge *bridge.Bridge,
+	ethRPC, peptideRPC client.RPC,
+	metrics *Metrics,
+	timeouts Timeouts,
+	logger log.Logger,
+) *engineServer {
+	return &engineServer{
+		interceptor: interceptor,
+		ibcBridge:   ibcBridge,
+		ethRPC:      withTimeoutErrors(ethRPC, gethEngine),
+		peptideRPC:  withTimeoutErrors(peptideRPC, abciEngine),
+		metrics:     metrics,
+		timeouts:    timeouts,
+		logger:      logger,
+	}
+}
+
+// ForkchoiceUpdatedV1 is ForkchoiceUpdatedV2 for pre-Shanghai networks, the payload attributes
+// carry no withdrawals.
+func (e *engineServer) ForkchoiceUpdatedV1(
+	ctx context.Context,
+	fcs eth.ForkchoiceState,
+	pa *eth.PayloadAttributes,
+) (*eth.ForkchoiceUpdatedResult, error) {
+	var attrs *eetypes.PayloadAttributesV3
+	if pa != nil {
+		attrs = &eetypes.PayloadAttributesV3{PayloadAttributes: *pa}
+	}
+
+	return e.forkchoiceUpdated(ctx, "engine_forkchoiceUpdatedV1", fcs, attrs)
 }
 
 func (e *engineServer) ForkchoiceUpdatedV2(
+	ctx context.Context,
 	fcs eth.ForkchoiceState,
 	pa *eth.PayloadAttributes,
 ) (*eth.ForkchoiceUpdatedResult, error) {
-	abciFcs, gethFcs := EngineForkStates(e.interceptor, fcs)
-	e.logger.Info("trying: ForkchoiceUpdatedV2", "abciFcs", abciFcs, "gethFcs", gethFcs, "pa", pa)
+	var attrs *eetypes.PayloadAttributesV3
+	if pa != nil {
+		attrs = &eetypes.PayloadAttributesV3{PayloadAttributes: *pa}
+	}
+
+	return e.forkchoiceUpdated(ctx, "engine_forkchoiceUpdatedV2", fcs, attrs)
+}
+
+// ForkchoiceUpdatedV3 is ForkchoiceUpdatedV2 with the Ecotone payload attributes.
+func (e *engineServer) ForkchoiceUpdatedV3(
+	ctx context.Context,
+	fcs eth.ForkchoiceState,
+	pa *eetypes.PayloadAttributesV3,
+) (*eth.ForkchoiceUpdatedResult, error) {
+	return e.forkchoiceUpdated(ctx, "engine_forkchoiceUpdatedV3", fcs, pa)
+}
+
+// GetPayloadV1 is GetPayloadV2 for pre-Shanghai networks, the payload is returned without an
+// envelope.
+func (e *engineServer) GetPayloadV1(ctx context.Context, payloadID eth.PayloadID) (*eth.ExecutionPayload, error) {
+	envelope, err := e.getPayload(ctx, "engine_getPayloadV1", payloadID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &envelope.ExecutionPayload.ExecutionPayload, nil
+}
+
+func (e *engineServer) GetPayloadV2(ctx context.Context, payloadID eth.PayloadID) (*eth.ExecutionPayloadEnvelope, error) {
+	envelope, err := e.getPayload(ctx, "engine_getPayloadV2", payloadID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eth.ExecutionPayloadEnvelope{ExecutionPayload: &envelope.ExecutionPayload.ExecutionPayload}, nil
+}
+
+// GetPayloadV3 is GetPayloadV2 returning the Ecotone envelope, which includes the blobs bundle
+// and the parent beacon block root.
+func (e *engineServer) GetPayloadV3(ctx context.Context, payloadID eth.PayloadID) (*eetypes.ExecutionPayloadEnvelopeV3, error) {
+	return e.getPayload(ctx, "engine_getPayloadV3", payloadID)
+}
+
+// NewPayloadV1 is NewPayloadV2 for pre-Shanghai networks.
+func (e *engineServer) NewPayloadV1(ctx context.Context, payload *eth.ExecutionPayload) (*eth.PayloadStatusV1, error) {
+	return e.newPayload(ctx, "engine_newPayloadV1", &eetypes.ExecutionPayloadV3{ExecutionPayload: *payload})
+}
+
+func (e *engineServer) NewPayloadV2(ctx context.Context, payload *eth.ExecutionPayload) (*eth.PayloadStatusV1, error) {
+	return e.newPayload(ctx, "engine_newPayloadV2", &eetypes.ExecutionPayloadV3{ExecutionPayload: *payload})
+}
+
+// NewPayloadV3 is NewPayloadV2 with the Ecotone payload, the expected blob versioned hashes and the
+// parent beacon block root. The latter two are forwarded to both engines unchanged.
+func (e *engineServer) NewPayloadV3(
+	ctx context.Context,
+	payload *eetypes.ExecutionPayloadV3,
+	versionedHashes []common.Hash,
+	beaconRoot *common.Hash,
+) (*eth.PayloadStatusV1, error) {
+	return e.newPayload(ctx, "engine_newPayloadV3", payload, versionedHashes, beaconRoot)
+}
+
+// GetPayloadBodiesByHashV1 returns the bodies of the composite blocks with the given hashes. Each
+// body holds the geth transactions and withdrawals along with the cosmos transactions of the abci
+// block. Unknown blocks are returned as null.
+func (e *engineServer) GetPayloadBodiesByHashV1(ctx context.Context, hashes []common.Hash) ([]*eetypes.CompositePayloadBody, error) {
+	e.logger.Info("trying: GetPayloadBodiesByHashV1", "hashes", hashes)
+
+	// Unknown composite hashes are translated to zero hashes, which both engines don't know either.
+	gethHashes, abciHashes := make([]common.Hash, len(hashes)), make([]common.Hash, len(hashes))
+	for i, hash := range hashes {
+		compositeBlock, err := e.interceptor.GetCompositeBlock(hash)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			return nil, err
+		}
+		gethHashes[i], abciHashes[i] = compositeBlock.GethHash, compositeBlock.ABCIHash
+	}
+
+	const method = "engine_getPayloadBodiesByHashV1"
+	ctx, cancel := e.timeouts.context(ctx, method)
+	defer cancel()
+
+	var gethResult, abciResult []*engine.ExecutionPayloadBodyV1
+	gethErr, abciErr := e.fanOut(ctx, method,
+		func(ctx context.Context) error {
+			return e.ethRPC.CallContext(ctx, &gethResult, method, gethHashes)
+		},
+		func(ctx context.Context) error {
+			return e.peptideRPC.CallContext(ctx, &abciResult, method, abciHashes)
+		},
+	)
+	if gethErr != nil {
+		e.logger.Error("failed to forward GetPayloadBodiesByHashV1 to geth engine", "error", gethErr)
+		return nil, gethErr
+	}
+	if abciErr != nil {
+		e.logger.Error("failed to forward GetPayloadBodiesByHashV1 to abci engine", "error", abciErr)
+		return nil, abciErr
+	}
+
+	result := combinePayloadBodies(gethResult, abciResult)
+
+	e.logger.Info("completed: GetPayloadBodiesByHashV1", "bodies", len(result))
+	return result, nil
+}
+
+// GetPayloadBodiesByRangeV1 returns the bodies of count composite blocks starting at the given
+// height, see GetPayloadBodiesByHashV1. Both engines share the block heights so the range is
+// forwarded as is.
+func (e *engineServer) GetPayloadBodiesByRangeV1(ctx context.Context, start, count hexutil.Uint64) ([]*eetypes.CompositePayloadBody, error) {
+	e.logger.Info("trying: GetPayloadBodiesByRangeV1", "start", start, "count", count)
+
+	const method = "engine_getPayloadBodiesByRangeV1"
+	ctx, cancel := e.timeouts.context(ctx, method)
+	defer cancel()
+
+	var gethResult, abciResult []*engine.ExecutionPayloadBodyV1
+	gethErr, abciErr := e.fanOut(ctx, method,
+		func(ctx context.Context) error {
+			return e.ethRPC.CallContext(ctx, &gethResult, method, start, count)
+		},
+		func(ctx context.Context) error {
+			return e.peptideRPC.CallContext(ctx, &abciResult, method, start, count)
+		},
+	)
+	if gethErr != nil {
+		e.logger.Error("failed to forward GetPayloadBodiesByRangeV1 to geth engine", "error", gethErr)
+		return nil, gethErr
+	}
+	if abciErr != nil {
+		e.logger.Error("failed to forward GetPayloadBodiesByRangeV1 to abci engine", "error", abciErr)
+		return nil, abciErr
+	}
+
+	result := combinePayloadBodies(gethResult, abciResult)
+
+	e.logger.Info("completed: GetPayloadBodiesByRangeV1", "bodies", len(result))
+	return result, nil
+}
+
+// ExchangeCapabilities returns the engine methods supported by the interceptor, which are the ones
+// implemented by the engineServer and supported by both geth and peptide. As per the spec the
+// capabilities of the caller are not taken into account and engine_exchangeCapabilities itself is
+// not listed.
+func (e *engineServer) ExchangeCapabilities(ctx context.Context, capabilities []string) ([]string, error) {
+	e.logger.Info("trying: ExchangeCapabilities", "capabilities", capabilities)
+
+	implemented := implementedEngineMethods()
+
+	var gethCapabilities, abciCapabilities []string
+	const method = "engine_exchangeCapabilities"
+	ctx, cancel := e.timeouts.context(ctx, method)
+	defer cancel()
+
+	gethErr, abciErr := e.fanOut(ctx, method,
+		func(ctx context.Context) (err error) {
+			gethCapabilities, err = exchangeCapabilities(ctx, e.ethRPC, implemented)
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			abciCapabilities, err = exchangeCapabilities(ctx, e.peptideRPC, implemented)
+			return err
+		},
+	)
+	if gethErr != nil {
+		e.logger.Error("failed to forward ExchangeCapabilities to geth engine", "error", gethErr)
+		return nil, gethErr
+	}
+	if abciErr != nil {
+		e.logger.Error("failed to forward ExchangeCapabilities to abci engine", "error", abciErr)
+		return nil, abciErr
+	}
+
+	result := intersectCapabilities(implemented, gethCapabilities, abciCapabilities)
+
+	e.logger.Info("completed: ExchangeCapabilities", "result", result)
+	return result, nil
+}
+
+// forkchoiceUpdated translates the composite fork choice state for both engines and forwards the
+// fork choice update to them using the given method, geth first. pa may be nil. The update is
+// only committed once both engines accepted it: if peptide fails or doesn't report the new head
+// as VALID, geth is rolled back to the previous composite head so the engines never diverge, and
+// the status of the engine that refused the update is returned.
+func (e *engineServer) forkchoiceUpdated(
+	ctx context.Context,
+	method string,
+	fcs eth.ForkchoiceState,
+	pa *eetypes.PayloadAttributesV3,
+) (*eth.ForkchoiceUpdatedResult, error) {
+	// Updates are applied one at a time so that a rollback restores the head of the last
+	// committed update.
+	e.fcuLock.Lock()
+	defer e.fcuLock.Unlock()
+
+	ctx, cancel := e.timeouts.context(ctx, method)
+	defer cancel()
+
+	abciFcs, gethFcs, err := EngineForkStates(e.interceptor, fcs)
+	if errors.Is(err, errUnknownHead) {
+		// We can't translate the head for the engines, as per the spec respond with SYNCING.
+		e.logger.Info("unknown head in "+method, "head", fcs.HeadBlockHash)
+		return &eth.ForkchoiceUpdatedResult{PayloadStatus: eth.PayloadStatusV1{Status: eth.ExecutionSyncing}}, nil
+	} else if err != nil {
+		e.logger.Error("failed to translate fork choice state", "error", err)
+		return nil, err
+	}
+	e.logger.Info("trying: "+method, "abciFcs", abciFcs, "gethFcs", gethFcs, "pa", pa)
 
 	var gethResult eth.ForkchoiceUpdatedResult
-	err := e.ethRPC.CallContext(context.TODO(), &gethResult, "engine_forkchoiceUpdatedV2", gethFcs, pa)
+	err = e.timeEngineCall(gethEngine, method, func() error {
+		return e.ethRPC.CallContext(ctx, &gethResult, method, gethFcs, pa)
+	})
 	if err != nil {
-		e.logger.Error("failed to forward ForkchoiceUpdatedV2 to geth engine", "error", err)
+		e.logger.Error("failed to forward "+method+" to geth engine", "error", err)
 		return nil, err
 	}
-	e.logger.Info("success in forwarding ForkchoiceUpdatedV2 to geth engine", "result", gethResult)
+	e.logger.Info("success in forwarding "+method+" to geth engine", "result", gethResult)
+	if gethResult.PayloadStatus.Status != eth.ExecutionValid {
+		// geth didn't move its head, leave peptide where it is as well.
+		e.logger.Info("geth engine did not accept "+method, "status", gethResult.PayloadStatus.Status)
+		status := eth.PayloadStatusV1{
+			Status:          gethResult.PayloadStatus.Status,
+			LatestValidHash: e.latestValidCompositeHash(&gethResult.PayloadStatus, nil),
+			ValidationError: gethResult.PayloadStatus.ValidationError,
+		}
+		return &eth.ForkchoiceUpdatedResult{PayloadStatus: status}, nil
+	}
+
+	// Messages are only included when a payload is built, forward them before the abci engine
+	// starts building so they land in this block rather than the next one.
+	if pa != nil {
+		// Deposits to the IBC bridge carry messages for peptide. They are part of the block
+		// whatever the state of the mempool, so they are forwarded directly rather than queued.
+		if err := e.forwardIBCBridgeDeposits(ctx, fcs.HeadBlockHash, pa.Transactions); err != nil {
+			e.logger.Error("failed to forward IBC bridge deposits to abci mempool, rolling back geth engine", "error", err)
+			if rollbackErr := e.rollbackGethHead(ctx, method); rollbackErr != nil {
+				return nil, errors.Join(err, rollbackErr)
+			}
+			return nil, err
+		}
+
+		// The sequencer excludes the transaction pool from some blocks, e.g. while it lags behind
+		// the L1, the messages are kept for the next block that includes it.
+		if pa.NoTxPool {
+			e.logger.Info("payload excludes the transaction pool, keeping mempool messages", "hasMsgs", e.interceptor.HasMsgs())
+		} else {
+			e.logger.Info("message mempool status: ", "hasMsgs", e.interceptor.HasMsgs())
+			if dropped := e.forwardMempoolMsgs(ctx); len(dropped) > 0 {
+				// The failures are reported by the mempool status.
+				e.logger.Error("dropped messages that could not be forwarded to abci mempool", "count", len(dropped))
+			}
+		}
+	}
 
 	// Forward to the abci engine.
-	e.logger.Info("forwarding ForkchoiceUpdatedV2 to abci engine")
+	e.logger.Info("forwarding " + method + " to abci engine")
 
 	var peptideResult eth.ForkchoiceUpdatedResult
-	err = e.peptideRPC.CallContext(context.TODO(), &peptideResult, "engine_forkchoiceUpdatedV2", abciFcs, pa)
+	err = e.timeEngineCall(abciEngine, method, func() error {
+		return e.peptideRPC.CallContext(ctx, &peptideResult, method, abciFcs, pa)
+	})
 	if err != nil {
-		e.logger.Error("failed to forward ForkchoiceUpdatedV2 to abci engine", "error", err)
+		e.logger.Error("failed to forward "+method+" to abci engine, rolling back geth engine", "error", err)
+		if rollbackErr := e.rollbackGethHead(ctx, method); rollbackErr != nil {
+			return nil, errors.Join(err, rollbackErr)
+		}
+		return nil, err
+	}
+	e.logger.Info("success in forwarding "+method+" to abci engine", "result", peptideResult)
+	if peptideResult.PayloadStatus.Status != eth.ExecutionValid {
+		e.logger.Info("abci engine did not accept "+method+", rolling back geth engine", "status", peptideResult.PayloadStatus.Status)
+		if err := e.rollbackGethHead(ctx, method); err != nil {
+			return nil, err
+		}
+		status := combinePayloadStatus(gethResult.PayloadStatus, peptideResult.PayloadStatus)
+		status.LatestValidHash = e.latestValidCompositeHash(&gethResult.PayloadStatus, &peptideResult.PayloadStatus)
+		return &eth.ForkchoiceUpdatedResult{PayloadStatus: status}, nil
 	}
-	e.logger.Info("success in forwarding ForkchoiceUpdatedV2 to abci engine", "result", peptideResult)
 
-	// TODO(jim): Crude at this point.
-	e.logger.Info("message mempool status: ", "hasMsgs", e.interceptor.HasMsgs())
-	if e.interceptor.HasMsgs() {
-		msgs := e.interceptor.GetMsgs()
+	// Combine payload ids and save them. Without payload attributes no payload is built and, as
+	// per the spec, the payload id is null.
+	if pa != nil {
+		compositePayload := eetypes.NewCompositePayload(gethResult.PayloadID, peptideResult.PayloadID)
+		compositePayload.ParentBeaconBlockRoot = pa.ParentBeaconBlockRoot
+		e.interceptor.SaveCompositePayload(compositePayload)
+		gethResult.PayloadID = compositePayload.Payload()
+	} else {
+		gethResult.PayloadID = nil
+	}
 
-		for _, msg := range msgs {
-			e.logger.Info("forwarding a message to abci mempool", "msg", msg)
-			err = e.peptideRPC.CallContext(context.TODO(), nil, "intercept_addMsgToTxMempool", msg)
-			if err != nil {
-				e.logger.Error("failed to forward message to abci mempool", "error", err)
-			}
+	// LatestValidHash of the Payload status should be our composite hash. Both engines report
+	// their new head as the latest valid block, fall back to it if either left it out.
+	gethLatestValidHash, abciLatestValidHash := gethFcs.HeadBlockHash, abciFcs.HeadBlockHash
+	if hash := gethResult.PayloadStatus.LatestValidHash; hash != nil {
+		gethLatestValidHash = *hash
+	}
+	if hash := peptideResult.PayloadStatus.LatestValidHash; hash != nil {
+		abciLatestValidHash = *hash
+	}
+	compositeLatestValidHash, err := e.saveCompositeBlock(gethLatestValidHash, abciLatestValidHash)
+	if err != nil {
+		return nil, err
+	}
+	gethResult.PayloadStatus.LatestValidHash = &compositeLatestValidHash
+
+	// Both engines moved, this is the head to roll back to from now on.
+	if err := e.interceptor.SaveCommittedForkchoiceState(fcs); err != nil {
+		e.logger.Error("failed to save committed fork choice state", "error", err)
+		return nil, err
+	}
+
+	// Composite blocks far enough below the finalized head are no longer needed.
+	if err := e.interceptor.PruneCompositeBlocks(fcs.FinalizedBlockHash); err != nil {
+		e.logger.Error("failed to prune composite blocks", "error", err)
+	}
+
+	e.logger.Info("completed: "+method, "result", gethResult)
+	return &gethResult, nil
+}
+
+// rollbackGethHead re-issues the last fork choice update both engines accepted to geth, undoing
+// the update it just accepted alone. Returns errNoCommittedState if no update was ever committed.
+// The rollback gets its own deadline as the one of the update may have expired already.
+func (e *engineServer) rollbackGethHead(ctx context.Context, method string) error {
+	committedFcs, err := e.interceptor.CommittedForkchoiceState()
+	if errors.Is(err, store.ErrNotFound) {
+		e.logger.Error("no committed fork choice state to roll geth engine back to")
+		return errNoCommittedState
+	} else if err != nil {
+		e.logger.Error("failed to load committed fork choice state", "error", err)
+		return err
+	}
+
+	_, gethFcs, err := EngineForkStates(e.interceptor, committedFcs)
+	if err != nil {
+		e.logger.Error("failed to translate committed fork choice state", "error", err)
+		return err
+	}
+
+	ctx, cancel := e.timeouts.context(context.WithoutCancel(ctx), method)
+	defer cancel()
+
+	var result eth.ForkchoiceUpdatedResult
+	if err := e.ethRPC.CallContext(ctx, &result, method, gethFcs, nil); err != nil {
+		e.logger.Error("failed to roll back geth engine", "head", gethFcs.HeadBlockHash, "error", err)
+		return err
+	}
+	if result.PayloadStatus.Status != eth.ExecutionValid {
+		e.logger.Error("geth engine did not accept rollback", "head", gethFcs.HeadBlockHash, "status", result.PayloadStatus.Status)
+		return fmt.Errorf("geth engine rollback to %s returned %s", gethFcs.HeadBlockHash, result.PayloadStatus.Status)
+	}
+
+	e.logger.Info("rolled back geth engine", "head", gethFcs.HeadBlockHash)
+	return nil
+}
+
+// latestValidCompositeHash translates the latest valid hash reported by the engines that did not
+// validate a payload to the composite block containing it, either status may be nil. Returns nil
+// if no composite block is known.
+func (e *engineServer) latestValidCompositeHash(gethStatus, abciStatus *eth.PayloadStatusV1) *common.Hash {
+	candidates := []struct {
+		status *eth.PayloadStatusV1
+		lookup func(common.Hash) (eetypes.CompositeBlock, error)
+	}{
+		{gethStatus, e.interceptor.GetCompositeBlockByGethHash},
+		{abciStatus, e.interceptor.GetCompositeBlockByABCIHash},
+	}
+
+	for _, c := range candidates {
+		// The latest valid hash of an engine that validated the payload is the refused block itself.
+		if c.status == nil || c.status.Status == eth.ExecutionValid || c.status.LatestValidHash == nil {
+			continue
+		}
+
+		compositeBlock, err := c.lookup(*c.status.LatestValidHash)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		} else if err != nil {
+			e.logger.Error("failed to look up latest valid block", "hash", c.status.LatestValidHash, "error", err)
+			continue
+		}
+		compositeHash := compositeBlock.Hash()
+		return &compositeHash
+	}
+
+	return nil
+}
+
+// forwardMempoolMsgs takes all messages out of the mempool and forwards them to the abci mempool,
+// each with its own timeout. Messages that could not be delivered, or not sent at all because ctx
+// is done, are requeued for the next call, messages rejected by peptide or that can't be requeued
+// are dropped, recorded in the mempool status and returned.
+func (e *engineServer) forwardMempoolMsgs(ctx context.Context) []*eetypes.MsgEnvelope {
+	var requeued, dropped []*eetypes.MsgEnvelope
+	msgs := e.interceptor.TakeMsgs()
+	for i, msg := range msgs {
+		if ctx.Err() != nil {
+			e.logger.Error("ran out of time forwarding messages to abci mempool, requeueing the rest", "count", len(msgs)-i, "error", ctx.Err())
+			requeued = append(requeued, msgs[i:]...)
+			break
+		}
+
+		e.logger.Info("forwarding a message to abci mempool", "id", msg.ID, "type", msg.TypeURL)
+		err := e.forwardMsg(ctx, msg)
+		switch {
+		case err == nil:
+		case isRetriableError(err):
+			e.logger.Error("failed to forward message to abci mempool, requeueing it", "id", msg.ID, "error", err)
+			requeued = append(requeued, msg)
+		default:
+			e.logger.Error("abci mempool rejected message, dropping it", "id", msg.ID, "type", msg.TypeURL, "sender", msg.Sender, "error", err)
+			e.interceptor.ReportFailedMsg(msg, err)
+			dropped = append(dropped, msg)
+		}
+	}
+
+	if len(requeued) > 0 {
+		for _, msg := range e.interceptor.RequeueMsgs(requeued) {
+			e.logger.Error("failed to requeue message, dropping it", "id", msg.ID, "type", msg.TypeURL, "sender", msg.Sender)
+			dropped = append(dropped, msg)
+		}
+	}
+
+	return dropped
+}
+
+// forwardMsg forwards a message to the abci mempool with its own timeout.
+func (e *engineServer) forwardMsg(ctx context.Context, msg *eetypes.MsgEnvelope) error {
+	ctx, cancel := e.timeouts.context(ctx, peptideAddMsgMethod)
+	defer cancel()
+
+	return e.peptideRPC.CallContext(ctx, nil, peptideAddMsgMethod, msg)
+}
+
+// forwardIBCBridgeDeposits forwards the messages of the IBC bridge calls among the forced
+// transactions of the payload attributes to the abci mempool. Deposits bypass the mempool, its
+// limits, eviction and TTL must not drop a transaction that is part of the block. Invalid calls
+// are skipped, geth decides on the validity of the transactions themselves, and deposits peptide
+// rejects are reported in the mempool status. Deposits already forwarded for a payload built on
+// the same parent are skipped as well, so that resent payload attributes don't replay them.
+// Returns an error if a deposit could not be delivered, the update must then be retried.
+func (e *engineServer) forwardIBCBridgeDeposits(ctx context.Context, parent common.Hash, txs []eth.Data) error {
+	if parent != e.depositsParent || e.forwardedDeposits == nil {
+		e.depositsParent, e.forwardedDeposits = parent, make(map[common.Hash]struct{})
+	}
+
+	for _, tx := range txs {
+		msg, isBridgeTx, err := IBCBridgeMsg(e.ibcBridge, tx)
+		if errors.Is(err, bridge.ErrUnsupportedCall) {
+			e.logger.Info("IBC bridge deposit has no message for abci engine, executed by geth alone", "error", err)
+			continue
+		} else if err != nil {
+			e.logger.Error("skipping invalid IBC bridge deposit", "error", err)
+			continue
+		}
+		if !isBridgeTx {
+			continue
+		}
+
+		if _, ok := e.forwardedDeposits[msg.Source]; ok {
+			e.logger.Info("IBC bridge deposit message already forwarded", "id", msg.ID, "source", msg.Source)
+			continue
+		}
+
+		e.logger.Info("forwarding IBC bridge deposit message to abci mempool", "id", msg.ID, "type", msg.TypeURL, "source", msg.Source)
+		switch err := e.forwardMsg(ctx, msg); {
+		case err == nil:
+		case isRetriableError(err):
+			return fmt.Errorf("forwarding IBC bridge deposit %s: %w", msg.Source, err)
+		default:
+			e.logger.Error("abci mempool rejected IBC bridge deposit message", "id", msg.ID, "type", msg.TypeURL, "source", msg.Source, "error", err)
+			e.interceptor.ReportFailedMsg(msg, err)
 		}
+		e.forwardedDeposits[msg.Source] = struct{}{}
 	}
 
-	// Combine payload ids and save them.
-	compositePayload := eetypes.NewCompositePayload(gethResult.PayloadID, peptideResult.PayloadID)
-	e.interceptor.SaveCompositePayload(compositePayload)
-	gethResult.PayloadID = compositePayload.Payload()
+	return nil
+}
+
+// queueIBCBridgeMsgs adds the messages of the IBC bridge transactions of a payload built or
+// imported by geth to the mempool, they are executed by peptide in the next payload built. Only
+// transactions that are part of a payload are considered, those rejected or dropped by geth never
+// reach peptide. Deposits are skipped, they are forwarded when the payload is built, see
+// forwardIBCBridgeDeposits. The sequencer imports the payloads it built, the messages still
+// queued for them are skipped as duplicates.
+func (e *engineServer) queueIBCBridgeMsgs(txs []eth.Data) {
+	for _, tx := range txs {
+		if len(tx) > 0 && tx[0] == types.DepositTxType {
+			continue
+		}
 
-	// LatestValidHash of the Payload status should be our composite hash.
-	compositeLatestValidHash := eetypes.NewCompositeBlock(*gethResult.PayloadStatus.LatestValidHash, *peptideResult.PayloadStatus.LatestValidHash)
-	e.interceptor.SaveCompositeBlock(compositeLatestValidHash)
-	compositeHash := compositeLatestValidHash.Hash()
-	gethResult.PayloadStatus.LatestValidHash = &compositeHash
+		msg, isBridgeTx, err := IBCBridgeMsg(e.ibcBridge, tx)
+		if errors.Is(err, bridge.ErrUnsupportedCall) {
+			e.logger.Info("IBC bridge transaction has no message for abci engine, executed by geth alone", "error", err)
+			continue
+		} else if err != nil {
+			e.logger.Error("skipping invalid IBC bridge transaction", "error", err)
+			continue
+		}
+		if !isBridgeTx {
+			continue
+		}
 
-	e.logger.Info("completed: ForkchoiceUpdatedV2", "error", err, "result", gethResult)
-	return &gethResult, err
+		e.logger.Info("queueing IBC bridge message", "id", msg.ID, "type", msg.TypeURL, "source", msg.Source)
+		if err := e.interceptor.AddMsgToMempool(msg); errors.Is(err, mempool.ErrDuplicateMsg) {
+			e.logger.Info("IBC bridge message already queued", "id", msg.ID, "source", msg.Source)
+		} else if err != nil {
+			e.logger.Error("failed to queue IBC bridge message", "id", msg.ID, "source", msg.Source, "error", err)
+		}
+	}
 }
 
-func (e *engineServer) GetPayloadV2(payloadID eth.PayloadID) (*eth.ExecutionPayloadEnvelope, error) {
+// getPayload retrieves the payloads built by both engines for the composite payload id using the
+// given method and combines them into a composite payload.
+func (e *engineServer) getPayload(ctx context.Context, method string, payloadID eth.PayloadID) (*eetypes.ExecutionPayloadEnvelopeV3, error) {
 	// Get payload for each of the engines.
-	compositePayload := e.interceptor.GetCompositePayload(payloadID)
+	compositePayload, err := e.interceptor.GetCompositePayload(payloadID)
+	if errors.Is(err, store.ErrNotFound) {
+		e.logger.Info("unknown payload in "+method, "payload_id", payloadID)
+		return nil, eetypes.UnknownPayload
+	} else if err != nil {
+		return nil, err
+	}
 	abciPayload, gethPayload := compositePayload.ABCIPayload, compositePayload.GethPayload
-	e.logger.Info("GetPayloadV2", "payload_id", payloadID, "abciPayload", abciPayload, "gethPayload", gethPayload)
+	e.logger.Info(method, "payload_id", payloadID, "abciPayload", abciPayload, "gethPayload", gethPayload)
 
-	var gethResult eth.ExecutionPayloadEnvelope
-	err := e.ethRPC.CallContext(context.TODO(), &gethResult, "engine_getPayloadV2", gethPayload)
+	ctx, cancel := e.timeouts.context(ctx, method)
+	defer cancel()
+
+	var gethResult, abciResult *eetypes.ExecutionPayloadEnvelopeV3
+	gethErr, abciErr := e.fanOut(ctx, method,
+		func(ctx context.Context) (err error) {
+			gethResult, err = callGetPayload(ctx, e.ethRPC, method, gethPayload)
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			abciResult, err = callGetPayload(ctx, e.peptideRPC, method, abciPayload)
+			return err
+		},
+	)
+	if gethErr != nil {
+		e.logger.Error("failed to forward "+method+" to geth engine", "error", gethErr)
+		return nil, gethErr
+	}
+	e.logger.Info("success in forwarding "+method+" to geth engine", "result", gethResult)
+	if abciErr != nil {
+		e.logger.Error("failed to forward "+method+" to abci engine", "error", abciErr)
+		return nil, abciErr
+	}
+	e.logger.Info("success in forwarding "+method+" to abci engine", "result", abciResult)
+
+	compositeBlock, err := e.interceptor.CompositeBlockOf(gethResult.ExecutionPayload.BlockHash, abciResult.ExecutionPayload.BlockHash)
 	if err != nil {
-		e.logger.Error("failed to forward GetPayloadV2 to geth engine", "error", err)
+		e.logger.Error("failed to look up composite block", "error", err)
 		return nil, err
 	}
-	e.logger.Info("success in forwarding GetPayloadV2 to geth engine", "result", gethResult)
-
-	// Forward to the abci engine.
-	e.logger.Info("forwarding GetPayloadV2 to abci engine")
+	blockNumber := uint64(gethResult.ExecutionPayload.BlockNumber)
+	if err := e.interceptor.SaveCompositeBlockWithNumber(compositeBlock, blockNumber); err != nil {
+		e.logger.Error("failed to save composite block", "error", err)
+		return nil, err
+	}
+	gethResult.ExecutionPayload.BlockHash = compositeBlock.Hash()
+	e.logger.Info("created composite block:", "combined hash", compositeBlock.Hash(), "gethHash", gethResult.ExecutionPayload.BlockHash, "abciHash", abciResult.ExecutionPayload.BlockHash)
 
-	var abciResult eth.ExecutionPayloadEnvelope
-	err = e.peptideRPC.CallContext(context.TODO(), &abciResult, "engine_getPayloadV2", abciPayload)
+	// The parent is usually stored already, reusing it keeps its hash if it was created under a
+	// previous hash scheme.
+	compositeParent, err := e.saveCompositeBlock(gethResult.ExecutionPayload.ParentHash, abciResult.ExecutionPayload.ParentHash)
 	if err != nil {
-		e.logger.Error("failed to forward GetPayloadV2 to abci engine", "error", err)
+		return nil, err
 	}
-	e.logger.Info("success in forwarding GetPayloadV2 to abci engine", "result", abciResult)
+	gethResult.ExecutionPayload.ParentHash = compositeParent
+	e.logger.Info("created composite parent:", "combined hash", compositeParent, "abciHash", abciResult.ExecutionPayload.ParentHash)
 
-	compositeBlock := eetypes.NewCompositeBlock(gethResult.ExecutionPayload.BlockHash, abciResult.ExecutionPayload.BlockHash)
-	e.interceptor.SaveCompositeBlock(compositeBlock)
-	gethResult.ExecutionPayload.BlockHash = compositeBlock.Hash()
-	e.logger.Info("created composite block:", "combined hash", compositeBlock.Hash(), "gethHash", gethResult.ExecutionPayload.BlockHash, "abciHash", abciResult.ExecutionPayload.BlockHash)
+	// The engines don't return the root the payload was built on, it was recorded when the
+	// build was started.
+	if gethResult.ParentBeaconBlockRoot == nil {
+		gethResult.ParentBeaconBlockRoot = compositePayload.ParentBeaconBlockRoot
+	}
 
-	compositeParent := eetypes.NewCompositeBlock(gethResult.ExecutionPayload.ParentHash, abciResult.ExecutionPayload.ParentHash)
-	e.interceptor.SaveCompositeBlock(compositeParent)
-	gethResult.ExecutionPayload.ParentHash = compositeParent.Hash()
-	e.logger.Info("created composite parent:", "combined hash", compositeParent.Hash(), "gethHash", gethResult.ExecutionPayload.ParentHash, "abciHash", abciResult.ExecutionPayload.ParentHash)
+	// The payload has been served, its id is not needed anymore.
+	e.interceptor.DeleteCompositePayload(payloadID)
 
-	e.logger.Info("completed: GetPayloadV2", "error", err, "result", gethResult.ExecutionPayload)
-	return &gethResult, err
+	e.queueIBCBridgeMsgs(gethResult.ExecutionPayload.Transactions)
+
+	e.logger.Info("completed: "+method, "result", gethResult.ExecutionPayload)
+	return gethResult, nil
 }
 
-func (e *engineServer) NewPayloadV2(payload *eth.ExecutionPayload) (*eth.PayloadStatusV1, error) {
-	compositeBlockHash := e.interceptor.GetCompositeBlock(payload.BlockHash)
-	compositeParentHash := e.interceptor.GetCompositeBlock(payload.ParentHash)
+// callGetPayload calls the get payload method on an engine and returns the result as an envelope.
+// engine_getPayloadV1 returns the bare execution payload, which is wrapped.
+func callGetPayload(ctx context.Context, rpcClient client.RPC, method string, payloadID *eth.PayloadID) (*eetypes.ExecutionPayloadEnvelopeV3, error) {
+	if method != "engine_getPayloadV1" {
+		var envelope eetypes.ExecutionPayloadEnvelopeV3
+		err := rpcClient.CallContext(ctx, &envelope, method, payloadID)
+		return &envelope, err
+	}
 
-	e.logger.Info("trying: NewPayloadV2", "payload.ID", payload.ID(), "blockHash", payload.BlockHash.Hex())
+	var payload eetypes.ExecutionPayloadV3
+	err := rpcClient.CallContext(ctx, &payload, method, payloadID)
+	return &eetypes.ExecutionPayloadEnvelopeV3{ExecutionPayload: &payload}, err
+}
 
-	payload.BlockHash = compositeBlockHash.GethHash
-	payload.ParentHash = compositeParentHash.GethHash
+// newPayload translates the composite block and parent hashes of the payload for both engines and
+// forwards the payload along with any extra params to them using the given method.
+func (e *engineServer) newPayload(ctx context.Context, method string, payload *eetypes.ExecutionPayloadV3, params ...any) (*eth.PayloadStatusV1, error) {
+	e.logger.Info("trying: "+method, "payload.ID", payload.ID(), "blockHash", payload.BlockHash.Hex())
+
+	// Without the composite blocks we can't tell the engines which blocks the payload refers to,
+	// respond with SYNCING rather than forwarding zero hashes.
+	compositeBlockHash, err := e.interceptor.GetCompositeBlock(payload.BlockHash)
+	if errors.Is(err, store.ErrNotFound) {
+		e.logger.Info("unknown block in "+method, "blockHash", payload.BlockHash)
+		return &eth.PayloadStatusV1{Status: eth.ExecutionSyncing}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	compositeParentHash, err := e.interceptor.GetCompositeBlock(payload.ParentHash)
+	if errors.Is(err, store.ErrNotFound) {
+		e.logger.Info("unknown parent in "+method, "parentHash", payload.ParentHash)
+		return &eth.PayloadStatusV1{Status: eth.ExecutionSyncing}, nil
+	} else if err != nil {
+		return nil, err
+	}
 
-	var gethResult eth.PayloadStatusV1
-	err := e.ethRPC.CallContext(context.TODO(), &gethResult, "engine_newPayloadV2", payload)
+	// Each engine gets its own copy of the payload as both are sent concurrently.
+	gethPayload, abciPayload := *payload, *payload
+	gethPayload.BlockHash, gethPayload.ParentHash = compositeBlockHash.GethHash, compositeParentHash.GethHash
+	abciPayload.BlockHash, abciPayload.ParentHash = compositeBlockHash.ABCIHash, compositeParentHash.ABCIHash
+
+	ctx, cancel := e.timeouts.context(ctx, method)
+	defer cancel()
+
+	var gethResult, abciResult eth.PayloadStatusV1
+	gethErr, abciErr := e.fanOut(ctx, method,
+		func(ctx context.Context) error {
+			return e.ethRPC.CallContext(ctx, &gethResult, method, append([]any{&gethPayload}, params...)...)
+		},
+		func(ctx context.Context) error {
+			return e.peptideRPC.CallContext(ctx, &abciResult, method, append([]any{&abciPayload}, params...)...)
+		},
+	)
+	if gethErr != nil {
+		e.logger.Error("failed to forward "+method+" to geth engine", "error", gethErr)
+		return nil, gethErr
+	}
+	if abciErr != nil {
+		e.logger.Error("failed to forward "+method+" to abci engine", "error", abciErr)
+		return nil, abciErr
+	}
+
+	result := combinePayloadStatus(gethResult, abciResult)
+	if result.Status != eth.ExecutionValid {
+		result.LatestValidHash = e.latestValidCompositeHash(&gethResult, &abciResult)
+		e.logger.Info("completed: "+method, "result", &result, "gethStatus", gethResult.Status, "abciStatus", abciResult.Status)
+		return &result, nil
+	}
+
+	// Combine latestValidHash and save it. Both engines validated the payload, they report it as
+	// the latest valid block, fall back to it if either left it out.
+	gethLatestValidHash, abciLatestValidHash := compositeBlockHash.GethHash, compositeBlockHash.ABCIHash
+	if gethResult.LatestValidHash != nil {
+		gethLatestValidHash = *gethResult.LatestValidHash
+	}
+	if abciResult.LatestValidHash != nil {
+		abciLatestValidHash = *abciResult.LatestValidHash
+	}
+	compositeBlock, err := e.interceptor.CompositeBlockOf(gethLatestValidHash, abciLatestValidHash)
 	if err != nil {
-		e.logger.Error("failed to forward NewPayloadV2 to geth engine", "error", err)
+		e.logger.Error("failed to look up composite block", "error", err)
 		return nil, err
 	}
+	// The validated block is indexed by its height so that it replaces a block built at the same
+	// height, e.g. when op-node imports a payload from the sequencer.
+	if err := e.interceptor.SaveCompositeBlockWithNumber(compositeBlock, uint64(payload.BlockNumber)); err != nil {
+		e.logger.Error("failed to save composite block", "error", err)
+		return nil, err
+	}
+	compositeLatestValidHash := compositeBlock.Hash()
+	result.LatestValidHash = &compositeLatestValidHash
 
-	e.logger.Info("forwarding NewPayloadV2 to abci engine")
+	e.queueIBCBridgeMsgs(payload.Transactions)
+
+	e.logger.Info("completed: "+method, "result", &result)
+	return &result, nil
+}
 
-	// TODO(jim): Is this re-use safe?
-	payload.BlockHash = compositeBlockHash.ABCIHash
-	payload.ParentHash = compositeParentHash.ABCIHash
-	var abciResult eth.PayloadStatusV1
-	err = e.peptideRPC.CallContext(context.TODO(), &abciResult, "engine_newPayloadV2", payload)
+// saveCompositeBlock saves the composite block of the geth and abci blocks and returns its hash.
+func (e *engineServer) saveCompositeBlock(gethHash, abciHash common.Hash) (common.Hash, error) {
+	compositeBlock, err := e.interceptor.CompositeBlockOf(gethHash, abciHash)
 	if err != nil {
-		e.logger.Error("failed to forward NewPayloadV2 to abci engine", "error", err)
+		e.logger.Error("failed to look up composite block", "error", err)
+		return common.Hash{}, err
+	}
+	if err := e.interceptor.SaveCompositeBlock(compositeBlock); err != nil {
+		e.logger.Error("failed to save composite block", "error", err)
+		return common.Hash{}, err
+	}
+
+	return compositeBlock.Hash(), nil
+}
+
+// exchangeCapabilities calls engine_exchangeCapabilities on an engine. Engines that predate the
+// method are assumed to support all the given capabilities.
+func exchangeCapabilities(ctx context.Context, rpcClient client.RPC, capabilities []string) ([]string, error) {
+	var result []string
+	err := rpcClient.CallContext(ctx, &result, "engine_exchangeCapabilities", capabilities)
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == methodNotFoundCode {
+		return capabilities, nil
 	}
 
-	// Combine latestValidHash and save it.
-	compositeLatestValidHash := eetypes.NewCompositeBlock(*gethResult.LatestValidHash, *abciResult.LatestValidHash)
-	e.interceptor.SaveCompositeBlock(compositeLatestValidHash)
-	compositeHash := compositeLatestValidHash.Hash()
-	gethResult.LatestValidHash = &compositeHash
+	return result, err
+}
+
+// implementedEngineMethods returns the sorted 'engine_' methods served by the engineServer,
+// excluding engine_exchangeCapabilities.
+func implementedEngineMethods() []string {
+	serverType := reflect.TypeOf(&engineServer{})
+
+	methods := make([]string, 0, serverType.NumMethod())
+	for i := 0; i < serverType.NumMethod(); i++ {
+		// Same name formatting as the rpc server uses when registering the service.
+		name := serverType.Method(i).Name
+		method := "engine_" + strings.ToLower(name[:1]) + name[1:]
+		if method != "engine_exchangeCapabilities" {
+			methods = append(methods, method)
+		}
+	}
+
+	sort.Strings(methods)
+	return methods
+}
+
+// intersectCapabilities returns the capabilities in base that are present in all others, keeping
+// the order of base.
+func intersectCapabilities(base []string, others ...[]string) []string {
+	result := make([]string, 0, len(base))
+	for _, capability := range base {
+		supported := true
+		for _, other := range others {
+			if !slices.Contains(other, capability) {
+				supported = false
+				break
+			}
+		}
+
+		if supported {
+			result = append(result, capability)
+		}
+	}
+
+	return result
+}
+
+// payloadStatusSeverity ranks the payload statuses, the combined status of both engines is the most
+// severe one. Unknown statuses rank as SYNCING, they don't tell whether the payload is valid.
+var payloadStatusSeverity = map[eth.ExecutePayloadStatus]int{
+	eth.ExecutionValid:            0,
+	eth.ExecutionAccepted:         1,
+	eth.ExecutionSyncing:          2,
+	eth.ExecutionInvalidBlockHash: 3,
+	eth.ExecutionInvalid:          4,
+}
+
+// combinePayloadStatus merges the payload statuses of geth and peptide into the one reported to
+// op-node: INVALID dominates, then SYNCING if either engine is syncing, and VALID only if both
+// engines validated the payload. The validation errors of both engines are concatenated. The
+// latest valid hash is left to the caller, it has to be translated to a composite hash.
+func combinePayloadStatus(gethStatus, abciStatus eth.PayloadStatusV1) eth.PayloadStatusV1 {
+	severity := func(status eth.ExecutePayloadStatus) int {
+		if s, ok := payloadStatusSeverity[status]; ok {
+			return s
+		}
+		return payloadStatusSeverity[eth.ExecutionSyncing]
+	}
+
+	result := eth.PayloadStatusV1{Status: gethStatus.Status}
+	if severity(abciStatus.Status) > severity(gethStatus.Status) {
+		result.Status = abciStatus.Status
+	}
+	if severity(result.Status) == payloadStatusSeverity[eth.ExecutionSyncing] {
+		result.Status = eth.ExecutionSyncing
+	}
+
+	var validationErrors []string
+	if gethStatus.ValidationError != nil && *gethStatus.ValidationError != "" {
+		validationErrors = append(validationErrors, "geth: "+*gethStatus.ValidationError)
+	}
+	if abciStatus.ValidationError != nil && *abciStatus.ValidationError != "" {
+		validationErrors = append(validationErrors, "peptide: "+*abciStatus.ValidationError)
+	}
+	if len(validationErrors) > 0 {
+		validationError := strings.Join(validationErrors, "; ")
+		result.ValidationError = &validationError
+	}
+
+	return result
+}
+
+// combinePayloadBodies merges the payload bodies returned by both engines by position. The geth
+// bodies determine the result, missing abci bodies leave the cosmos transactions empty.
+func combinePayloadBodies(gethBodies, abciBodies []*engine.ExecutionPayloadBodyV1) []*eetypes.CompositePayloadBody {
+	bodies := make([]*eetypes.CompositePayloadBody, len(gethBodies))
+	for i, gethBody := range gethBodies {
+		var abciBody *engine.ExecutionPayloadBodyV1
+		if i < len(abciBodies) {
+			abciBody = abciBodies[i]
+		}
+
+		bodies[i] = eetypes.NewCompositePayloadBody(gethBody, abciBody)
+	}
 
-	e.logger.Info("completed: NewPayloadV2", "error", err, "result", &gethResult)
-	return &gethResult, err
+	return bodies
 }
@@ -0,0 +1,963 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/cometbft/cometbft/libs/log"
+
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+
+	"github.com/ibc-scouts/ibc-interceptor/node/bridge"
+	"github.com/ibc-scouts/ibc-interceptor/node/store"
+	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
+)
+
+func TestImplementedEngineMethods(t *testing.T) {
+	methods := implementedEngineMethods()
+
+	for _, method := range []string{
+		"engine_forkchoiceUpdatedV1",
+		"engine_forkchoiceUpdatedV2",
+		"engine_forkchoiceUpdatedV3",
+		"engine_getPayloadV1",
+		"engine_getPayloadV2",
+		"engine_getPayloadV3",
+		"engine_newPayloadV1",
+		"engine_newPayloadV2",
+		"engine_newPayloadV3",
+	} {
+		require.Contains(t, methods, method)
+	}
+	require.NotContains(t, methods, "engine_exchangeCapabilities")
+	require.IsNonDecreasing(t, methods)
+}
+
+func TestIntersectCapabilities(t *testing.T) {
+	base := []string{"engine_a", "engine_b", "engine_c"}
+
+	require.Equal(t, []string{"engine_b"}, intersectCapabilities(base, []string{"engine_b", "engine_c"}, []string{"engine_a", "engine_b"}))
+	require.Equal(t, base, intersectCapabilities(base, base))
+	require.Empty(t, intersectCapabilities(base, []string{"engine_d"}))
+}
+
+func TestCombinePayloadBodies(t *testing.T) {
+	gethBodies := []*engine.ExecutionPayloadBodyV1{
+		{TransactionData: []hexutil.Bytes{{0x01}}},
+		nil,
+		{TransactionData: []hexutil.Bytes{{0x03}}},
+	}
+	abciBodies := []*engine.ExecutionPayloadBodyV1{
+		{TransactionData: []hexutil.Bytes{{0x0a}}},
+		{TransactionData: []hexutil.Bytes{{0x0b}}},
+	}
+
+	bodies := combinePayloadBodies(gethBodies, abciBodies)
+	require.Len(t, bodies, 3)
+
+	require.Equal(t, gethBodies[0].TransactionData, bodies[0].TransactionData)
+	require.Equal(t, abciBodies[0].TransactionData, bodies[0].CosmosTransactions)
+	// unknown geth blocks stay null.
+	require.Nil(t, bodies[1])
+	// missing abci bodies leave the cosmos transactions empty.
+	require.Equal(t, gethBodies[2].TransactionData, bodies[2].TransactionData)
+	require.Empty(t, bodies[2].CosmosTransactions)
+}
+
+func TestGetPayloadBodiesByHashV1(t *testing.T) {
+	first := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x02"))
+	second := eetypes.NewCompositeBlock(common.HexToHash("0x03"), common.HexToHash("0x04"))
+	unknown := common.HexToHash("0xff")
+
+	testCases := []struct {
+		name    string
+		abciErr error
+	}{
+		{"success", nil},
+		{"abci engine fails", errors.New("connection refused")},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			interceptor := mockInterceptor{newMockMempool(), newMockBlockStore(first, second), mockPayloadStore{}}
+			// Each engine is asked for its own blocks, unknown composite blocks are zero hashes.
+			ethRPC := &mockRPC{handle: func(method string, args ...any) (any, error) {
+				require.Equal(t, "engine_getPayloadBodiesByHashV1", method)
+				require.Equal(t, []common.Hash{second.GethHash, {}, first.GethHash}, args[0])
+				return []*engine.ExecutionPayloadBodyV1{{TransactionData: []hexutil.Bytes{{0x03}}}, nil, {TransactionData: []hexutil.Bytes{{0x01}}}}, nil
+			}}
+			peptideRPC := &mockRPC{handle: func(method string, args ...any) (any, error) {
+				require.Equal(t, "engine_getPayloadBodiesByHashV1", method)
+				require.Equal(t, []common.Hash{second.ABCIHash, {}, first.ABCIHash}, args[0])
+				return []*engine.ExecutionPayloadBodyV1{{TransactionData: []hexutil.Bytes{{0x04}}}, nil, {TransactionData: []hexutil.Bytes{{0x02}}}}, tc.abciErr
+			}}
+			server := newEngineAPI(interceptor, nil, ethRPC, peptideRPC, NopMetrics(), Timeouts{}, log.NewNopLogger())
+
+			bodies, err := server.GetPayloadBodiesByHashV1(context.Background(), []common.Hash{second.Hash(), unknown, first.Hash()})
+			if tc.abciErr != nil {
+				require.ErrorIs(t, err, tc.abciErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, bodies, 3)
+			require.Equal(t, []hexutil.Bytes{{0x03}}, bodies[0].TransactionData)
+			require.Equal(t, []hexutil.Bytes{{0x04}}, bodies[0].CosmosTransactions)
+			require.Nil(t, bodies[1])
+			require.Equal(t, []hexutil.Bytes{{0x01}}, bodies[2].TransactionData)
+			require.Equal(t, []hexutil.Bytes{{0x02}}, bodies[2].CosmosTransactions)
+		})
+	}
+}
+
+func TestGetPayloadBodiesByRangeV1(t *testing.T) {
+	testCases := []struct {
+		name    string
+		abciErr error
+	}{
+		{"success", nil},
+		{"abci engine fails", errors.New("connection refused")},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// Both engines share the block heights, the range is forwarded as is.
+			engineBodies := func(tx byte, err error) func(string, ...any) (any, error) {
+				return func(method string, args ...any) (any, error) {
+					require.Equal(t, "engine_getPayloadBodiesByRangeV1", method)
+					require.Equal(t, []any{hexutil.Uint64(5), hexutil.Uint64(1)}, args)
+					return []*engine.ExecutionPayloadBodyV1{{TransactionData: []hexutil.Bytes{{tx}}}}, err
+				}
+			}
+			ethRPC := &mockRPC{handle: engineBodies(0x01, nil)}
+			peptideRPC := &mockRPC{handle: engineBodies(0x02, tc.abciErr)}
+			interceptor := mockInterceptor{newMockMempool(), newMockBlockStore(), mockPayloadStore{}}
+			server := newEngineAPI(interceptor, nil, ethRPC, peptideRPC, NopMetrics(), Timeouts{}, log.NewNopLogger())
+
+			bodies, err := server.GetPayloadBodiesByRangeV1(context.Background(), 5, 1)
+			if tc.abciErr != nil {
+				require.ErrorIs(t, err, tc.abciErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, bodies, 1)
+			require.Equal(t, []hexutil.Bytes{{0x01}}, bodies[0].TransactionData)
+			require.Equal(t, []hexutil.Bytes{{0x02}}, bodies[0].CosmosTransactions)
+		})
+	}
+}
+
+// mockPayloadStore is an in-memory PayloadStore.
+type mockPayloadStore map[eth.PayloadID]eetypes.CompositePayload
+
+var _ PayloadStore = mockPayloadStore{}
+
+func (m mockPayloadStore) GetCompositePayload(id eth.PayloadID) (eetypes.CompositePayload, error) {
+	payload, ok := m[id]
+	if !ok {
+		return eetypes.CompositePayload{}, store.ErrNotFound
+	}
+	return payload, nil
+}
+
+func (m mockPayloadStore) SaveCompositePayload(payload eetypes.CompositePayload) {
+	m[*payload.Payload()] = payload
+}
+
+func (m mockPayloadStore) DeleteCompositePayload(id eth.PayloadID) {
+	delete(m, id)
+}
+
+// mockInterceptor is an Interceptor made of the in-memory mocks.
+type mockInterceptor struct {
+	*mockMempool
+	mockBlockStore
+	mockPayloadStore
+}
+
+// mockRPCError is an error response of a JSON-RPC server.
+type mockRPCError struct{}
+
+func (mockRPCError) Error() string  { return "rejected" }
+func (mockRPCError) ErrorCode() int { return -32000 }
+
+// validForkchoiceUpdate returns a handler answering fork choice updates of an engine at head.
+func validForkchoiceUpdate(head common.Hash, payloadID eth.PayloadID) func(string, ...any) (any, error) {
+	return func(string, ...any) (any, error) {
+		return eth.ForkchoiceUpdatedResult{
+			PayloadStatus: eth.PayloadStatusV1{Status: eth.ExecutionValid, LatestValidHash: &head},
+			PayloadID:     &payloadID,
+		}, nil
+	}
+}
+
+func TestForkchoiceUpdatedForwardsMempoolMsgsOnce(t *testing.T) {
+	head := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x02"))
+	fcs := eth.ForkchoiceState{HeadBlockHash: head.Hash()}
+	signer := sdk.AccAddress(make([]byte, 20)).String()
+	msg, err := eetypes.NewMsgEnvelope(channeltypes.NewMsgChannelOpenConfirm("transfer", "channel-0", []byte("proof"), clienttypes.NewHeight(0, 1), signer), time.Now())
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		// errs are the errors returned by peptide for the successive forwarding attempts.
+		errs        []error
+		expAttempts int
+		expDropped  bool
+	}{
+		{"delivered", nil, 1, false},
+		{"unreachable peptide: requeued and delivered by the next update", []error{errors.New("connection refused")}, 2, false},
+		{"rejected by peptide: dropped", []error{mockRPCError{}}, 1, true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			interceptor := mockInterceptor{newMockMempool(), newMockBlockStore(head), mockPayloadStore{}}
+			require.NoError(t, interceptor.AddMsgToMempool(msg))
+
+			var attempts int
+			peptideFCU := validForkchoiceUpdate(head.ABCIHash, eth.PayloadID{0x02})
+			peptideRPC := &mockRPC{handle: func(method string, args ...any) (any, error) {
+				if method != "intercept_addMsgToTxMempool" {
+					return peptideFCU(method, args...)
+				}
+
+				require.Equal(t, msg, args[0])
+				attempts++
+				if attempts <= len(tc.errs) {
+					return nil, tc.errs[attempts-1]
+				}
+				return nil, nil
+			}}
+			ethRPC := &mockRPC{handle: validForkchoiceUpdate(head.GethHash, eth.PayloadID{0x01})}
+			server := newEngineAPI(interceptor, nil, ethRPC, peptideRPC, NopMetrics(), Timeouts{}, log.NewNopLogger())
+
+			for i := 0; i < 3; i++ {
+				_, err := server.ForkchoiceUpdatedV2(context.Background(), fcs, &eth.PayloadAttributes{})
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tc.expAttempts, attempts)
+			require.False(t, interceptor.HasMsgs())
+		})
+	}
+}
+
+func TestForwardMempoolMsgs(t *testing.T) {
+	signer := sdk.AccAddress(make([]byte, 20)).String()
+	newMsg := func(channel string) *eetypes.MsgEnvelope {
+		msg, err := eetypes.NewMsgEnvelope(channeltypes.NewMsgChannelOpenConfirm("transfer", channel, []byte("proof"), clienttypes.NewHeight(0, 1), signer), time.Now())
+		require.NoError(t, err)
+		return msg
+	}
+	delivered, retriable, rejected := newMsg("channel-0"), newMsg("channel-1"), newMsg("channel-2")
+
+	peptideRPC := &mockRPC{handle: func(_ string, args ...any) (any, error) {
+		switch args[0] {
+		case retriable:
+			return nil, context.DeadlineExceeded
+		case rejected:
+			return nil, mockRPCError{}
+		default:
+			return nil, nil
+		}
+	}}
+	interceptor := mockInterceptor{newMockMempool(), newMockBlockStore(), mockPayloadStore{}}
+	for _, msg := range []*eetypes.MsgEnvelope{delivered, retriable, rejected} {
+		require.NoError(t, interceptor.AddMsgToMempool(msg))
+	}
+	server := newEngineAPI(interceptor, nil, nil, peptideRPC, NopMetrics(), Timeouts{}, log.NewNopLogger())
+
+	dropped := server.forwardMempoolMsgs(context.Background())
+	require.Equal(t, []*eetypes.MsgEnvelope{rejected}, dropped)
+	require.Equal(t, []*eetypes.MsgEnvelope{retriable}, interceptor.GetMsgs())
+
+	// The dropped message is reported by cosmos_mempoolStatus along with the peptide error.
+	status := newCosmosAPI(interceptor, nil, Timeouts{}, log.NewNopLogger()).MempoolStatus()
+	require.Len(t, status.RecentFailures, 1)
+	require.Equal(t, rejected, status.RecentFailures[0].MsgEnvelope)
+	require.Equal(t, mockRPCError{}.Error(), status.RecentFailures[0].Reason)
+}
+
+func TestForkchoiceUpdatedForwardsMempoolMsgsWhenBuilding(t *testing.T) {
+	head := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x02"))
+	fcs := eth.ForkchoiceState{HeadBlockHash: head.Hash()}
+	signer := sdk.AccAddress(make([]byte, 20)).String()
+	msg, err := eetypes.NewMsgEnvelope(channeltypes.NewMsgChannelOpenConfirm("transfer", "channel-0", []byte("proof"), clienttypes.NewHeight(0, 1), signer), time.Now())
+	require.NoError(t, err)
+
+	interceptor := mockInterceptor{newMockMempool(), newMockBlockStore(head), mockPayloadStore{}}
+	require.NoError(t, interceptor.AddMsgToMempool(msg))
+
+	var peptideCalls []string
+	peptideFCU := validForkchoiceUpdate(head.ABCIHash, eth.PayloadID{0x02})
+	peptideRPC := &mockRPC{handle: func(method string, args ...any) (any, error) {
+		peptideCalls = append(peptideCalls, method)
+		return peptideFCU(method, args...)
+	}}
+	ethRPC := &mockRPC{handle: validForkchoiceUpdate(head.GethHash, eth.PayloadID{0x01})}
+	server := newEngineAPI(interceptor, nil, ethRPC, peptideRPC, NopMetrics(), Timeouts{}, log.NewNopLogger())
+
+	// Head updates leave the messages in the mempool and build no payload.
+	result, err := server.ForkchoiceUpdatedV2(context.Background(), fcs, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"engine_forkchoiceUpdatedV2"}, peptideCalls)
+	require.True(t, interceptor.HasMsgs())
+	require.Nil(t, result.PayloadID)
+	require.Empty(t, interceptor.mockPayloadStore)
+
+	// Payloads that exclude the transaction pool leave them in the mempool as well.
+	peptideCalls = nil
+	result, err = server.ForkchoiceUpdatedV2(context.Background(), fcs, &eth.PayloadAttributes{NoTxPool: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{"engine_forkchoiceUpdatedV2"}, peptideCalls)
+	require.True(t, interceptor.HasMsgs())
+	require.NotNil(t, result.PayloadID)
+
+	// Building a payload forwards them before the abci engine starts building.
+	peptideCalls = nil
+	result, err = server.ForkchoiceUpdatedV2(context.Background(), fcs, &eth.PayloadAttributes{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"intercept_addMsgToTxMempool", "engine_forkchoiceUpdatedV2"}, peptideCalls)
+	require.False(t, interceptor.HasMsgs())
+	require.NotNil(t, result.PayloadID)
+	require.Len(t, interceptor.mockPayloadStore, 1)
+}
+
+func TestForkchoiceUpdatedRollback(t *testing.T) {
+	prev := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x02"))
+	next := eetypes.NewCompositeBlock(common.HexToHash("0x03"), common.HexToHash("0x04"))
+	validationErr := "invalid state root"
+
+	testCases := []struct {
+		name string
+		// geth and peptide answer the update to next.
+		geth    func(string, ...any) (any, error)
+		peptide func(string, ...any) (any, error)
+		// expGethHeads are the heads geth is updated to after the update to prev.
+		expGethHeads   []common.Hash
+		expPeptideCall bool
+		expStatus      *eth.PayloadStatusV1
+		expErr         bool
+	}{
+		{
+			"both engines accept",
+			validForkchoiceUpdate(next.GethHash, eth.PayloadID{0x01}),
+			validForkchoiceUpdate(next.ABCIHash, eth.PayloadID{0x02}),
+			[]common.Hash{next.GethHash},
+			true,
+			&eth.PayloadStatusV1{Status: eth.ExecutionValid, LatestValidHash: ptr(next.Hash())},
+			false,
+		},
+		{
+			"geth refuses: peptide left untouched",
+			func(string, ...any) (any, error) {
+				return eth.ForkchoiceUpdatedResult{PayloadStatus: eth.PayloadStatusV1{
+					Status: eth.ExecutionInvalid, LatestValidHash: &prev.GethHash, ValidationError: &validationErr,
+				}}, nil
+			},
+			nil,
+			[]common.Hash{next.GethHash},
+			false,
+			&eth.PayloadStatusV1{Status: eth.ExecutionInvalid, LatestValidHash: ptr(prev.Hash()), ValidationError: &validationErr},
+			false,
+		},
+		{
+			"peptide fails: geth rolled back",
+			validForkchoiceUpdate(next.GethHash, eth.PayloadID{0x01}),
+			func(string, ...any) (any, error) { return nil, errors.New("connection refused") },
+			[]common.Hash{next.GethHash, prev.GethHash},
+			true,
+			nil,
+			true,
+		},
+		{
+			"peptide syncing: geth rolled back",
+			validForkchoiceUpdate(next.GethHash, eth.PayloadID{0x01}),
+			func(string, ...any) (any, error) {
+				return eth.ForkchoiceUpdatedResult{PayloadStatus: eth.PayloadStatusV1{Status: eth.ExecutionSyncing}}, nil
+			},
+			[]common.Hash{next.GethHash, prev.GethHash},
+			true,
+			&eth.PayloadStatusV1{Status: eth.ExecutionSyncing},
+			false,
+		},
+		{
+			"peptide invalid: geth rolled back",
+			validForkchoiceUpdate(next.GethHash, eth.PayloadID{0x01}),
+			func(string, ...any) (any, error) {
+				return eth.ForkchoiceUpdatedResult{PayloadStatus: eth.PayloadStatusV1{
+					Status: eth.ExecutionInvalid, LatestValidHash: &prev.ABCIHash, ValidationError: &validationErr,
+				}}, nil
+			},
+			[]common.Hash{next.GethHash, prev.GethHash},
+			true,
+			&eth.PayloadStatusV1{Status: eth.ExecutionInvalid, LatestValidHash: ptr(prev.Hash()), ValidationError: ptr("peptide: " + validationErr)},
+			false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			interceptor := mockInterceptor{newMockMempool(), newMockBlockStore(prev, next), mockPayloadStore{}}
+
+			var gethHeads []common.Hash
+			var peptideCalled bool
+			ethRPC := &mockRPC{handle: func(method string, args ...any) (any, error) {
+				head := args[0].(eth.ForkchoiceState).HeadBlockHash
+				gethHeads = append(gethHeads, head)
+				if head == prev.GethHash {
+					return validForkchoiceUpdate(prev.GethHash, eth.PayloadID{})(method, args...)
+				}
+				return tc.geth(method, args...)
+			}}
+			peptideRPC := &mockRPC{handle: func(method string, args ...any) (any, error) {
+				if args[0].(eth.ForkchoiceState).HeadBlockHash == prev.ABCIHash {
+					return validForkchoiceUpdate(prev.ABCIHash, eth.PayloadID{})(method, args...)
+				}
+				peptideCalled = true
+				return tc.peptide(method, args...)
+			}}
+			server := newEngineAPI(interceptor, nil, ethRPC, peptideRPC, NopMetrics(), Timeouts{}, log.NewNopLogger())
+
+			// Commit prev as the head to roll back to.
+			_, err := server.ForkchoiceUpdatedV2(context.Background(), eth.ForkchoiceState{HeadBlockHash: prev.Hash()}, nil)
+			require.NoError(t, err)
+			gethHeads = nil
+
+			result, err := server.ForkchoiceUpdatedV2(context.Background(), eth.ForkchoiceState{HeadBlockHash: next.Hash()}, nil)
+			require.Equal(t, tc.expGethHeads, gethHeads)
+			require.Equal(t, tc.expPeptideCall, peptideCalled)
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, *tc.expStatus, result.PayloadStatus)
+		})
+	}
+}
+
+func TestForkchoiceUpdatedRollbackCommittedState(t *testing.T) {
+	prev := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x02"))
+	next := eetypes.NewCompositeBlock(common.HexToHash("0x03"), common.HexToHash("0x04"))
+
+	interceptor := mockInterceptor{newMockMempool(), newMockBlockStore(prev, next), mockPayloadStore{}}
+	var gethHeads []common.Hash
+	ethRPC := &mockRPC{handle: func(method string, args ...any) (any, error) {
+		head := args[0].(eth.ForkchoiceState).HeadBlockHash
+		gethHeads = append(gethHeads, head)
+		return validForkchoiceUpdate(head, eth.PayloadID{})(method, args...)
+	}}
+	peptideRefuses := false
+	peptideRPC := &mockRPC{handle: func(method string, args ...any) (any, error) {
+		if peptideRefuses {
+			return nil, errors.New("connection refused")
+		}
+		return validForkchoiceUpdate(args[0].(eth.ForkchoiceState).HeadBlockHash, eth.PayloadID{})(method, args...)
+	}}
+	newServer := func() *engineServer {
+		return newEngineAPI(interceptor, nil, ethRPC, peptideRPC, NopMetrics(), Timeouts{}, log.NewNopLogger())
+	}
+
+	// Without a committed state geth can't be rolled back, which is reported.
+	peptideRefuses = true
+	_, err := newServer().ForkchoiceUpdatedV2(context.Background(), eth.ForkchoiceState{HeadBlockHash: next.Hash()}, nil)
+	require.ErrorIs(t, err, errNoCommittedState)
+	require.Equal(t, []common.Hash{next.GethHash}, gethHeads)
+
+	// The committed state is saved in the block store.
+	peptideRefuses = false
+	_, err = newServer().ForkchoiceUpdatedV2(context.Background(), eth.ForkchoiceState{HeadBlockHash: prev.Hash()}, nil)
+	require.NoError(t, err)
+	committed, err := interceptor.CommittedForkchoiceState()
+	require.NoError(t, err)
+	require.Equal(t, eth.ForkchoiceState{HeadBlockHash: prev.Hash()}, committed)
+
+	// A restarted interceptor rolls geth back to the state committed before the restart.
+	gethHeads = nil
+	peptideRefuses = true
+	_, err = newServer().ForkchoiceUpdatedV2(context.Background(), eth.ForkchoiceState{HeadBlockHash: next.Hash()}, nil)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, errNoCommittedState)
+	require.Equal(t, []common.Hash{next.GethHash, prev.GethHash}, gethHeads)
+}
+
+// pruningInterceptor records the finalized hashes composite blocks are pruned at.
+type pruningInterceptor struct {
+	mockInterceptor
+	finalized []common.Hash
+}
+
+func (p *pruningInterceptor) PruneCompositeBlocks(finalized common.Hash) error {
+	p.finalized = append(p.finalized, finalized)
+	return nil
+}
+
+func TestForkchoiceUpdatedPrunesCompositeBlocks(t *testing.T) {
+	head := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x02"))
+	finalized := eetypes.NewCompositeBlock(common.HexToHash("0x03"), common.HexToHash("0x04"))
+	fcs := eth.ForkchoiceState{HeadBlockHash: head.Hash(), SafeBlockHash: finalized.Hash(), FinalizedBlockHash: finalized.Hash()}
+
+	interceptor := &pruningInterceptor{mockInterceptor: mockInterceptor{newMockMempool(), newMockBlockStore(head, finalized), mockPayloadStore{}}}
+	gethStatus := eth.ExecutionValid
+	ethRPC := &mockRPC{handle: func(string, ...any) (any, error) {
+		return eth.ForkchoiceUpdatedResult{PayloadStatus: eth.PayloadStatusV1{Status: gethStatus, LatestValidHash: &head.GethHash}}, nil
+	}}
+	peptideRPC := &mockRPC{handle: validForkchoiceUpdate(head.ABCIHash, eth.PayloadID{})}
+	server := newEngineAPI(interceptor, nil, ethRPC, peptideRPC, NopMetrics(), Timeouts{}, log.NewNopLogger())
+
+	// Committed updates prune below the composite finalized hash.
+	_, err := server.ForkchoiceUpdatedV2(context.Background(), fcs, nil)
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{finalized.Hash()}, interceptor.finalized)
+
+	// Refused updates leave the store untouched.
+	gethStatus = eth.ExecutionInvalid
+	_, err = server.ForkchoiceUpdatedV2(context.Background(), fcs, nil)
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{finalized.Hash()}, interceptor.finalized)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestCombinePayloadStatus(t *testing.T) {
+	status := func(status eth.ExecutePayloadStatus, validationErr string) eth.PayloadStatusV1 {
+		result := eth.PayloadStatusV1{Status: status}
+		if validationErr != "" {
+			result.ValidationError = &validationErr
+		}
+		return result
+	}
+	valid, accepted, syncing := status(eth.ExecutionValid, ""), status(eth.ExecutionAccepted, ""), status(eth.ExecutionSyncing, "")
+
+	testCases := []struct {
+		name      string
+		geth      eth.PayloadStatusV1
+		abci      eth.PayloadStatusV1
+		expStatus eth.PayloadStatusV1
+	}{
+		{"both valid", valid, valid, valid},
+		{"geth accepted", accepted, valid, accepted},
+		{"abci accepted", valid, accepted, accepted},
+		{"geth syncing", syncing, valid, syncing},
+		{"abci syncing", accepted, syncing, syncing},
+		{"unknown abci status counts as syncing", valid, status("", ""), syncing},
+		{
+			"geth invalid",
+			status(eth.ExecutionInvalid, "bad state root"),
+			syncing,
+			status(eth.ExecutionInvalid, "geth: bad state root"),
+		},
+		{
+			"abci invalid",
+			valid,
+			status(eth.ExecutionInvalid, "bad app hash"),
+			status(eth.ExecutionInvalid, "peptide: bad app hash"),
+		},
+		{
+			"invalid dominates invalid block hash",
+			status(eth.ExecutionInvalidBlockHash, "bad block hash"),
+			status(eth.ExecutionInvalid, "bad app hash"),
+			status(eth.ExecutionInvalid, "geth: bad block hash; peptide: bad app hash"),
+		},
+		{
+			"invalid block hash dominates syncing",
+			syncing,
+			status(eth.ExecutionInvalidBlockHash, ""),
+			status(eth.ExecutionInvalidBlockHash, ""),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expStatus, combinePayloadStatus(tc.geth, tc.abci))
+		})
+	}
+}
+
+func TestNewPayloadV3(t *testing.T) {
+	parent := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x02"))
+	block := eetypes.NewCompositeBlock(common.HexToHash("0x03"), common.HexToHash("0x04"))
+	versionedHashes := []common.Hash{common.HexToHash("0xaa"), common.HexToHash("0xbb")}
+	beaconRoot := common.HexToHash("0xcc")
+
+	interceptor := mockInterceptor{newMockMempool(), newMockBlockStore(parent, block), mockPayloadStore{}}
+
+	// engineNewPayload answers as an engine at head, recording the params it received.
+	engineNewPayload := func(head common.Hash, params *[]any) func(string, ...any) (any, error) {
+		return func(method string, args ...any) (any, error) {
+			require.Equal(t, "engine_newPayloadV3", method)
+			*params = args
+			return eth.PayloadStatusV1{Status: eth.ExecutionValid, LatestValidHash: &head}, nil
+		}
+	}
+	var gethParams, abciParams []any
+	ethRPC := &mockRPC{handle: engineNewPayload(block.GethHash, &gethParams)}
+	peptideRPC := &mockRPC{handle: engineNewPayload(block.ABCIHash, &abciParams)}
+	server := newEngineAPI(interceptor, nil, ethRPC, peptideRPC, NopMetrics(), Timeouts{}, log.NewNopLogger())
+
+	payload := &eetypes.ExecutionPayloadV3{ExecutionPayload: eth.ExecutionPayload{BlockHash: block.Hash(), ParentHash: parent.Hash(), BlockNumber: 2}}
+	status, err := server.NewPayloadV3(context.Background(), payload, versionedHashes, &beaconRoot)
+	require.NoError(t, err)
+	require.Equal(t, eth.ExecutionValid, status.Status)
+	require.Equal(t, block.Hash(), *status.LatestValidHash)
+	// The validated block is indexed by its height.
+	saved, err := interceptor.GetCompositeBlockByNumber(2)
+	require.NoError(t, err)
+	require.Equal(t, block, saved)
+
+	// Each engine gets its own hashes and the Ecotone params unchanged.
+	for _, tc := range []struct {
+		params                []any
+		blockHash, parentHash common.Hash
+	}{
+		{gethParams, block.GethHash, parent.GethHash},
+		{abciParams, block.ABCIHash, parent.ABCIHash},
+	} {
+		require.Len(t, tc.params, 3)
+		forwarded := tc.params[0].(*eetypes.ExecutionPayloadV3)
+		require.Equal(t, tc.blockHash, forwarded.BlockHash)
+		require.Equal(t, tc.parentHash, forwarded.ParentHash)
+		require.Equal(t, versionedHashes, tc.params[1])
+		require.Equal(t, &beaconRoot, tc.params[2])
+	}
+	// The caller's payload is left untouched.
+	require.Equal(t, block.Hash(), payload.BlockHash)
+}
+
+func TestGetPayloadV3ParentBeaconBlockRoot(t *testing.T) {
+	head := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x02"))
+	built := eetypes.NewCompositeBlock(common.HexToHash("0x03"), common.HexToHash("0x04"))
+	beaconRoot := common.HexToHash("0xcc")
+
+	interceptor := mockInterceptor{newMockMempool(), newMockBlockStore(head), mockPayloadStore{}}
+
+	// engine answers the fork choice update at head and returns the payload built on it.
+	engine := func(head, built common.Hash, payloadID eth.PayloadID) func(string, ...any) (any, error) {
+		return func(method string, args ...any) (any, error) {
+			switch method {
+			case "engine_forkchoiceUpdatedV3":
+				// the attributes are forwarded with the root, the engines don't return it.
+				require.Equal(t, &beaconRoot, args[1].(*eetypes.PayloadAttributesV3).ParentBeaconBlockRoot)
+				return validForkchoiceUpdate(head, payloadID)(method, args...)
+			case "engine_getPayloadV3":
+				require.Equal(t, &payloadID, args[0])
+				return eetypes.ExecutionPayloadEnvelopeV3{ExecutionPayload: &eetypes.ExecutionPayloadV3{
+					ExecutionPayload: eth.ExecutionPayload{BlockHash: built, ParentHash: head, BlockNumber: 1},
+				}}, nil
+			}
+			return nil, errors.New("unexpected method " + method)
+		}
+	}
+	ethRPC := &mockRPC{handle: engine(head.GethHash, built.GethHash, eth.PayloadID{0x01})}
+	peptideRPC := &mockRPC{handle: engine(head.ABCIHash, built.ABCIHash, eth.PayloadID{0x02})}
+	server := newEngineAPI(interceptor, nil, ethRPC, peptideRPC, NopMetrics(), Timeouts{}, log.NewNopLogger())
+
+	pa := &eetypes.PayloadAttributesV3{ParentBeaconBlockRoot: &beaconRoot}
+	result, err := server.ForkchoiceUpdatedV3(context.Background(), eth.ForkchoiceState{HeadBlockHash: head.Hash()}, pa)
+	require.NoError(t, err)
+	require.NotNil(t, result.PayloadID)
+
+	envelope, err := server.GetPayloadV3(context.Background(), *result.PayloadID)
+	require.NoError(t, err)
+	require.Equal(t, &beaconRoot, envelope.ParentBeaconBlockRoot)
+	require.Equal(t, built.Hash(), envelope.ExecutionPayload.BlockHash)
+	require.Equal(t, head.Hash(), envelope.ExecutionPayload.ParentHash)
+
+	// The payload has been served.
+	_, err = server.GetPayloadV3(context.Background(), *result.PayloadID)
+	require.ErrorIs(t, err, eetypes.UnknownPayload)
+}
+
+func TestEngineV1Methods(t *testing.T) {
+	parent := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x02"))
+	built := eetypes.NewCompositeBlock(common.HexToHash("0x03"), common.HexToHash("0x04"))
+
+	interceptor := mockInterceptor{newMockMempool(), newMockBlockStore(parent), mockPayloadStore{}}
+
+	// engine is a pre-Shanghai engine at parent building built, it only speaks the V1 methods.
+	var (
+		mtx     sync.Mutex
+		methods []string
+	)
+	engine := func(parent, built common.Hash, payloadID eth.PayloadID) func(string, ...any) (any, error) {
+		return func(method string, args ...any) (any, error) {
+			mtx.Lock()
+			methods = append(methods, method)
+			mtx.Unlock()
+			switch method {
+			case "engine_forkchoiceUpdatedV1":
+				require.Equal(t, parent, args[0].(eth.ForkchoiceState).HeadBlockHash)
+				return validForkchoiceUpdate(parent, payloadID)(method, args...)
+			case "engine_getPayloadV1":
+				require.Equal(t, &payloadID, args[0])
+				// V1 returns the bare execution payload, without an envelope.
+				return &eth.ExecutionPayload{BlockHash: built, ParentHash: parent, BlockNumber: 1}, nil
+			case "engine_newPayloadV1":
+				payload := args[0].(*eetypes.ExecutionPayloadV3)
+				require.Equal(t, built, payload.BlockHash)
+				require.Equal(t, parent, payload.ParentHash)
+				return eth.PayloadStatusV1{Status: eth.ExecutionValid, LatestValidHash: &built}, nil
+			}
+			return nil, errors.New("unexpected method " + method)
+		}
+	}
+	ethRPC := &mockRPC{handle: engine(parent.GethHash, built.GethHash, eth.PayloadID{0x01})}
+	peptideRPC := &mockRPC{handle: engine(parent.ABCIHash, built.ABCIHash, eth.PayloadID{0x02})}
+	server := newEngineAPI(interceptor, nil, ethRPC, peptideRPC, NopMetrics(), Timeouts{}, log.NewNopLogger())
+
+	result, err := server.ForkchoiceUpdatedV1(context.Background(), eth.ForkchoiceState{HeadBlockHash: parent.Hash()}, &eth.PayloadAttributes{})
+	require.NoError(t, err)
+	require.Equal(t, eth.ExecutionValid, result.PayloadStatus.Status)
+	require.Equal(t, parent.Hash(), *result.PayloadStatus.LatestValidHash)
+	require.NotNil(t, result.PayloadID)
+
+	payload, err := server.GetPayloadV1(context.Background(), *result.PayloadID)
+	require.NoError(t, err)
+	require.Equal(t, built.Hash(), payload.BlockHash)
+	require.Equal(t, parent.Hash(), payload.ParentHash)
+	require.Equal(t, eth.Uint64Quantity(1), payload.BlockNumber)
+
+	status, err := server.NewPayloadV1(context.Background(), payload)
+	require.NoError(t, err)
+	require.Equal(t, eth.ExecutionValid, status.Status)
+	require.Equal(t, built.Hash(), *status.LatestValidHash)
+
+	require.ElementsMatch(t, []string{
+		"engine_forkchoiceUpdatedV1", "engine_forkchoiceUpdatedV1",
+		"engine_getPayloadV1", "engine_getPayloadV1",
+		"engine_newPayloadV1", "engine_newPayloadV1",
+	}, methods)
+}
+
+func TestForkchoiceUpdatedForwardsIBCBridgeDeposits(t *testing.T) {
+	head := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x02"))
+	next := eetypes.NewCompositeBlock(common.HexToHash("0x03"), common.HexToHash("0x04"))
+
+	ibcBridge, err := bridge.NewBridge(nil)
+	require.NoError(t, err)
+	bridgeABI, err := abi.JSON(strings.NewReader(bridge.IBCStandardBridgeABI))
+	require.NoError(t, err)
+	bridgeAddress := common.HexToAddress(bridge.DefaultAddress)
+	transfer, err := bridgeABI.Pack("transfer", "transfer", "channel-0", "stake", big.NewInt(100), "cosmos1receiver", uint64(0), uint64(10), uint64(0), "")
+	require.NoError(t, err)
+	// deposit makes the same transfer from the same sender, only the source hash differs.
+	deposit := func(source common.Hash) eth.Data {
+		bz, err := types.NewTx(&types.DepositTx{SourceHash: source, From: common.HexToAddress("0xaa"), To: &bridgeAddress, Data: transfer}).MarshalBinary()
+		require.NoError(t, err)
+		return bz
+	}
+	first, second, third := deposit(common.HexToHash("0x01")), deposit(common.HexToHash("0x02")), deposit(common.HexToHash("0x03"))
+	fourth, fifth, sixth := deposit(common.HexToHash("0x04")), deposit(common.HexToHash("0x05")), deposit(common.HexToHash("0x06"))
+
+	interceptor := mockInterceptor{newMockMempool(), newMockBlockStore(head, next), mockPayloadStore{}}
+	var sources []common.Hash
+	var depositErr error
+	peptideRPC := &mockRPC{handle: func(method string, args ...any) (any, error) {
+		if method == "intercept_addMsgToTxMempool" {
+			sources = append(sources, args[0].(*eetypes.MsgEnvelope).Source)
+			return nil, depositErr
+		}
+		return validForkchoiceUpdate(args[0].(eth.ForkchoiceState).HeadBlockHash, eth.PayloadID{0x02})(method, args...)
+	}}
+	var gethHeads []common.Hash
+	ethRPC := &mockRPC{handle: func(method string, args ...any) (any, error) {
+		gethHeads = append(gethHeads, args[0].(eth.ForkchoiceState).HeadBlockHash)
+		return validForkchoiceUpdate(args[0].(eth.ForkchoiceState).HeadBlockHash, eth.PayloadID{0x01})(method, args...)
+	}}
+	server := newEngineAPI(interceptor, ibcBridge, ethRPC, peptideRPC, NopMetrics(), Timeouts{}, log.NewNopLogger())
+	tryBuild := func(head eetypes.CompositeBlock, txs ...eth.Data) error {
+		_, err := server.ForkchoiceUpdatedV2(context.Background(), eth.ForkchoiceState{HeadBlockHash: head.Hash()}, &eth.PayloadAttributes{Transactions: txs})
+		return err
+	}
+	build := func(head eetypes.CompositeBlock, txs ...eth.Data) {
+		require.NoError(t, tryBuild(head, txs...))
+	}
+
+	// Identical calls of distinct deposits are all forwarded.
+	build(head, first, second)
+	require.Equal(t, []common.Hash{crypto.Keccak256Hash(first), crypto.Keccak256Hash(second)}, sources)
+
+	// Resending the payload attributes doesn't replay the deposits already forwarded.
+	sources = nil
+	build(head, first, second)
+	require.Empty(t, sources)
+
+	// Deposits of the next block are forwarded, directly rather than through the mempool.
+	build(next, third)
+	require.Equal(t, []common.Hash{crypto.Keccak256Hash(third)}, sources)
+	require.False(t, interceptor.HasMsgs())
+
+	// Deposits that could not be delivered fail the update, geth is rolled back and the deposits
+	// are forwarded again when the update is retried.
+	sources, gethHeads, depositErr = nil, nil, context.DeadlineExceeded
+	require.ErrorIs(t, tryBuild(head, fourth), context.DeadlineExceeded)
+	require.Equal(t, []common.Hash{head.GethHash, next.GethHash}, gethHeads)
+	depositErr = nil
+	build(head, fourth)
+	require.Equal(t, []common.Hash{crypto.Keccak256Hash(fourth), crypto.Keccak256Hash(fourth)}, sources)
+
+	// Deposits peptide rejects are reported rather than failing the update.
+	sources, depositErr = nil, mockRPCError{}
+	build(head, fifth)
+	require.Equal(t, []common.Hash{crypto.Keccak256Hash(fifth)}, sources)
+	require.Len(t, interceptor.MempoolStatus().RecentFailures, 1)
+
+	// Payloads that exclude the transaction pool still execute their deposits.
+	sources, depositErr = nil, nil
+	_, err = server.ForkchoiceUpdatedV2(context.Background(), eth.ForkchoiceState{HeadBlockHash: next.Hash()}, &eth.PayloadAttributes{Transactions: []eth.Data{sixth}, NoTxPool: true})
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{crypto.Keccak256Hash(sixth)}, sources)
+}
+
+func TestNewPayloadQueuesIBCBridgeMsgs(t *testing.T) {
+	parent := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x02"))
+	block := eetypes.NewCompositeBlock(common.HexToHash("0x03"), common.HexToHash("0x04"))
+
+	ibcBridge, err := bridge.NewBridge(nil)
+	require.NoError(t, err)
+	bridgeABI, err := abi.JSON(strings.NewReader(bridge.IBCStandardBridgeABI))
+	require.NoError(t, err)
+	bridgeAddress := common.HexToAddress(bridge.DefaultAddress)
+	other := common.HexToAddress("0x01")
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	chainID := big.NewInt(1)
+	signed := func(to *common.Address, value int64, data []byte) eth.Data {
+		tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{ChainID: chainID, To: to, Value: big.NewInt(value), Data: data})
+		require.NoError(t, err)
+		bz, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		return bz
+	}
+	transfer, err := bridgeABI.Pack("transfer", "transfer", "channel-0", "stake", big.NewInt(100), "cosmos1receiver", uint64(0), uint64(10), uint64(0), "")
+	require.NoError(t, err)
+	sendPacket, err := bridgeABI.Pack("sendPacket", "transfer", "channel-0", uint64(0), uint64(10), uint64(0), []byte("data"))
+	require.NoError(t, err)
+	deposit, err := types.NewTx(&types.DepositTx{SourceHash: common.HexToHash("0x01"), From: common.HexToAddress("0xaa"), To: &bridgeAddress, Data: transfer}).MarshalBinary()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		tx        eth.Data
+		status    eth.ExecutePayloadStatus
+		expQueued bool
+	}{
+		{"bridge call: queued", signed(&bridgeAddress, 0, transfer), eth.ExecutionValid, true},
+		{"bridge call of an invalid payload: not queued", signed(&bridgeAddress, 0, transfer), eth.ExecutionInvalid, false},
+		{"deposit: forwarded with the fork choice update", deposit, eth.ExecutionValid, false},
+		{"value transfer to the bridge: not queued", signed(&bridgeAddress, 1, nil), eth.ExecutionValid, false},
+		{"unknown bridge method: not queued", signed(&bridgeAddress, 0, []byte{0xde, 0xad, 0xbe, 0xef}), eth.ExecutionValid, false},
+		{"send packet: not queued", signed(&bridgeAddress, 0, sendPacket), eth.ExecutionValid, false},
+		{"invalid bridge call: not queued", signed(&bridgeAddress, 0, transfer[:36]), eth.ExecutionValid, false},
+		{"tx to another address: not queued", signed(&other, 0, transfer), eth.ExecutionValid, false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			interceptor := mockInterceptor{newMockMempool(), newMockBlockStore(parent, block), mockPayloadStore{}}
+			engineNewPayload := func(head common.Hash) func(string, ...any) (any, error) {
+				return func(string, ...any) (any, error) {
+					return eth.PayloadStatusV1{Status: tc.status, LatestValidHash: &head}, nil
+				}
+			}
+			ethRPC := &mockRPC{handle: engineNewPayload(block.GethHash)}
+			peptideRPC := &mockRPC{handle: engineNewPayload(block.ABCIHash)}
+			server := newEngineAPI(interceptor, ibcBridge, ethRPC, peptideRPC, NopMetrics(), Timeouts{}, log.NewNopLogger())
+
+			payload := &eth.ExecutionPayload{BlockHash: block.Hash(), ParentHash: parent.Hash(), Transactions: []eth.Data{tc.tx}}
+			_, err := server.NewPayloadV2(context.Background(), payload)
+			require.NoError(t, err)
+			require.Equal(t, tc.expQueued, interceptor.HasMsgs())
+			if tc.expQueued {
+				require.Equal(t, crypto.Keccak256Hash(tc.tx), interceptor.GetMsgs()[0].Source)
+			}
+		})
+	}
+}
+
+func TestGetPayloadQueuesIBCBridgeMsgs(t *testing.T) {
+	head := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x02"))
+	built := eetypes.NewCompositeBlock(common.HexToHash("0x03"), common.HexToHash("0x04"))
+
+	ibcBridge, err := bridge.NewBridge(nil)
+	require.NoError(t, err)
+	bridgeABI, err := abi.JSON(strings.NewReader(bridge.IBCStandardBridgeABI))
+	require.NoError(t, err)
+	bridgeAddress := common.HexToAddress(bridge.DefaultAddress)
+	transfer, err := bridgeABI.Pack("transfer", "transfer", "channel-0", "stake", big.NewInt(100), "cosmos1receiver", uint64(0), uint64(10), uint64(0), "")
+	require.NoError(t, err)
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	chainID := big.NewInt(1)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{ChainID: chainID, To: &bridgeAddress, Data: transfer})
+	require.NoError(t, err)
+	bridgeTx, err := tx.MarshalBinary()
+	require.NoError(t, err)
+
+	interceptor := mockInterceptor{newMockMempool(), newMockBlockStore(head), mockPayloadStore{}}
+
+	// engine builds a payload with the bridge transaction on head and validates it.
+	engine := func(head, built common.Hash, payloadID eth.PayloadID) func(string, ...any) (any, error) {
+		return func(method string, args ...any) (any, error) {
+			switch method {
+			case "engine_forkchoiceUpdatedV2":
+				return validForkchoiceUpdate(head, payloadID)(method, args...)
+			case "engine_getPayloadV2":
+				return eetypes.ExecutionPayloadEnvelopeV3{ExecutionPayload: &eetypes.ExecutionPayloadV3{
+					ExecutionPayload: eth.ExecutionPayload{BlockHash: built, ParentHash: head, BlockNumber: 1, Transactions: []eth.Data{bridgeTx}},
+				}}, nil
+			case "engine_newPayloadV2":
+				return eth.PayloadStatusV1{Status: eth.ExecutionValid, LatestValidHash: &built}, nil
+			}
+			return nil, errors.New("unexpected method " + method)
+		}
+	}
+	ethRPC := &mockRPC{handle: engine(head.GethHash, built.GethHash, eth.PayloadID{0x01})}
+	peptideRPC := &mockRPC{handle: engine(head.ABCIHash, built.ABCIHash, eth.PayloadID{0x02})}
+	server := newEngineAPI(interceptor, ibcBridge, ethRPC, peptideRPC, NopMetrics(), Timeouts{}, log.NewNopLogger())
+
+	result, err := server.ForkchoiceUpdatedV2(context.Background(), eth.ForkchoiceState{HeadBlockHash: head.Hash()}, &eth.PayloadAttributes{})
+	require.NoError(t, err)
+	require.False(t, interceptor.HasMsgs())
+
+	// The message of the bridge transaction in the built payload is queued for the next one.
+	envelope, err := server.GetPayloadV2(context.Background(), *result.PayloadID)
+	require.NoError(t, err)
+	require.Len(t, interceptor.GetMsgs(), 1)
+
+	// The sequencer imports the payload it built, the message is only queued once.
+	status, err := server.NewPayloadV2(context.Background(), envelope.ExecutionPayload)
+	require.NoError(t, err)
+	require.Equal(t, eth.ExecutionValid, status.Status)
+	require.Len(t, interceptor.GetMsgs(), 1)
+	require.Equal(t, crypto.Keccak256Hash(bridgeTx), interceptor.GetMsgs()[0].Source)
+}
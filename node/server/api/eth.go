@@ -2,6 +2,8 @@ package api
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -11,6 +13,7 @@ import (
 
 	"github.com/cometbft/cometbft/libs/log"
 
+	"github.com/ibc-scouts/ibc-interceptor/node/store"
 	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
 )
 
@@ -27,37 +30,53 @@ type ethServer struct {
 	blockStore BlockStore
 	ethRPC     client.RPC
 	peptideRPC client.RPC
-	logger     log.Logger
+	// timeouts bound the calls forwarded to the engines by each method.
+	timeouts Timeouts
+	logger   log.Logger
 }
 
 // newEthAPI returns a new execEngineAPI.
-func newEthAPI(blockStore BlockStore, ethRPC, peptideRPC client.RPC, logger log.Logger) *ethServer {
-	return &ethServer{blockStore, ethRPC, peptideRPC, logger}
+func newEthAPI(blockStore BlockStore, ethRPC, peptideRPC client.RPC, timeouts Timeouts, logger log.Logger) *ethServer {
+	return &ethServer{
+		blockStore,
+		withTimeoutErrors(ethRPC, gethEngine),
+		withTimeoutErrors(peptideRPC, abciEngine),
+		timeouts,
+		logger,
+	}
 }
 
-func GetEthAPI(blockStore BlockStore, ethRPC, peptideRPC client.RPC, logger log.Logger) rpc.API {
+func GetEthAPI(blockStore BlockStore, ethRPC, peptideRPC client.RPC, timeouts Timeouts, logger log.Logger) rpc.API {
 	return rpc.API{
 		Namespace: "eth",
-		Service:   newEthAPI(blockStore, ethRPC, peptideRPC, logger),
+		Service:   newEthAPI(blockStore, ethRPC, peptideRPC, timeouts, logger),
 	}
 }
 
-// Added to be able to intercept and forward eth transactions.
-func (e *ethServer) SendRawTransaction(data hexutil.Bytes) (common.Hash, error) {
+// Added to be able to intercept and forward eth transactions. The messages of IBC bridge
+// transactions are only queued for peptide once the transactions are part of a payload, see
+// engineServer.queueIBCBridgeMsgs.
+func (e *ethServer) SendRawTransaction(ctx context.Context, data hexutil.Bytes) (common.Hash, error) {
+	ctx, cancel := e.timeouts.context(ctx, "eth_sendRawTransaction")
+	defer cancel()
+
 	e.logger.Info("trying: SendRawTransaction")
 
 	var result common.Hash
-	err := e.ethRPC.CallContext(context.TODO(), &result, "eth_sendRawTransaction", data)
+	err := e.ethRPC.CallContext(ctx, &result, "eth_sendRawTransaction", data)
 
 	e.logger.Info("completed: SendRawTransaction", "error", err, "result", result)
 	return result, err
 }
 
-func (e *ethServer) ChainId() (hexutil.Big, error) { // nolint: revive, stylecheck
+func (e *ethServer) ChainId(ctx context.Context) (hexutil.Big, error) { // nolint: revive, stylecheck
+	ctx, cancel := e.timeouts.context(ctx, "eth_chainId")
+	defer cancel()
+
 	e.logger.Info("trying: ChainID")
 
 	var id hexutil.Big
-	err := e.ethRPC.CallContext(context.TODO(), &id, "eth_chainId")
+	err := e.ethRPC.CallContext(ctx, &id, "eth_chainId")
 
 	e.logger.Info("completed: ChainID", "id", id, "error", err)
 	return id, err
@@ -66,11 +85,14 @@ func (e *ethServer) ChainId() (hexutil.Big, error) { // nolint: revive, styleche
 // Docu yanked from go-eth for fullTx.
 //   - When fullTx is true all transactions in the block are returned, otherwise
 //     only the transaction hash is returned.
-func (e *ethServer) GetBlockByNumber(id any, fullTx bool) (map[string]any, error) {
+func (e *ethServer) GetBlockByNumber(ctx context.Context, id any, fullTx bool) (map[string]any, error) {
+	ctx, cancel := e.timeouts.context(ctx, "eth_getBlockByNumber")
+	defer cancel()
+
 	e.logger.Info("trying: GetBlockByNumber", "id", id)
 
 	var gethResult map[string]any
-	err := e.ethRPC.CallContext(context.TODO(), &gethResult, "eth_getBlockByNumber", id, fullTx)
+	err := e.ethRPC.CallContext(ctx, &gethResult, "eth_getBlockByNumber", id, fullTx)
 	if err != nil {
 		e.logger.Error("failed to call geth", "error", err)
 		// TODO(jim): What do we do if geth for some reason errs and we dont? This happens when
@@ -79,28 +101,97 @@ func (e *ethServer) GetBlockByNumber(id any, fullTx bool) (map[string]any, error
 		return nil, err
 	}
 
-	var abciResult map[string]any
-	err = e.peptideRPC.CallContext(context.TODO(), &abciResult, "eth_getBlockByNumber", id, fullTx)
-	if err != nil {
-		e.logger.Error("failed to call abci", "error", err)
+	if gethResult == nil {
+		// Unknown blocks are returned as null, same as geth does.
+		e.logger.Info("completed: GetBlockByNumber, unknown block", "id", id)
+		return nil, nil
 	}
 
 	// Combine the hashes and store the composite block, return the composite hash as the geth["hash"] field.
 	// See monomers ToEthBlock for fields populated in the abci call.
-	gethHash := common.HexToHash(gethResult["hash"].(string))
-	abciHash := common.HexToHash(abciResult["hash"].(string))
-	compositeBlock := eetypes.NewCompositeBlock(gethHash, abciHash)
-	e.blockStore.SaveCompositeBlock(compositeBlock)
+	gethHash, err := blockField(gethResult, "hash")
+	if err != nil {
+		e.logger.Error("invalid geth block", "error", err)
+		return nil, err
+	}
+	blockNumber, err := blockNumberField(gethResult)
+	if err != nil {
+		e.logger.Error("invalid geth block", "error", err)
+		return nil, err
+	}
+
+	// Tags such as "latest" may resolve to different heights on both engines when a fork choice
+	// update lands in between, ask peptide for the height geth resolved.
+	var abciResult map[string]any
+	if err := e.peptideRPC.CallContext(ctx, &abciResult, "eth_getBlockByNumber", hexutil.Uint64(blockNumber), fullTx); err != nil {
+		e.logger.Error("failed to call abci", "error", err)
+		return nil, err
+	}
+	if abciResult == nil {
+		e.logger.Error("abci block not found", "number", blockNumber)
+		return nil, fmt.Errorf("abci block %d not found", blockNumber)
+	}
+	abciHash, err := blockField(abciResult, "hash")
+	if err != nil {
+		e.logger.Error("invalid abci block", "error", err)
+		return nil, err
+	}
+	abciNumber, err := blockNumberField(abciResult)
+	if err != nil {
+		e.logger.Error("invalid abci block", "error", err)
+		return nil, err
+	}
+	if abciNumber != blockNumber {
+		e.logger.Error("abci block height mismatch", "number", blockNumber, "abciNumber", abciNumber)
+		return nil, fmt.Errorf("abci block %d returned for geth block %d", abciNumber, blockNumber)
+	}
+
+	compositeBlock, err := e.blockStore.CompositeBlockOf(common.HexToHash(gethHash), common.HexToHash(abciHash))
+	if err != nil {
+		e.logger.Error("failed to look up composite block", "error", err)
+		return nil, err
+	}
+	if err := e.blockStore.SaveCompositeBlockWithNumber(compositeBlock, blockNumber); err != nil {
+		e.logger.Error("failed to save composite block", "error", err)
+		return nil, err
+	}
 
 	gethResult["hash"] = compositeBlock.Hash()
 
 	e.logger.Info("composite block", "compositeHash", compositeBlock.Hash().Hex())
 	e.logger.Info("completed: GetBlockByNumber", "result", gethResult)
-	return gethResult, err
+	return gethResult, nil
+}
+
+// blockField returns the string field of a JSON block, an error if it is missing or not a string.
+func blockField(block map[string]any, key string) (string, error) {
+	value, ok := block[key]
+	if !ok {
+		return "", fmt.Errorf("block field %q missing", key)
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("block field %q is a %T, expected a string", key, value)
+	}
+
+	return str, nil
+}
+
+// blockNumberField returns the decoded number of a JSON block.
+func blockNumberField(block map[string]any) (uint64, error) {
+	number, err := blockField(block, "number")
+	if err != nil {
+		return 0, err
+	}
+
+	return hexutil.DecodeUint64(number)
 }
 
 // Added for completeness -- tests do not appear to invoke for time being.
-func (e *ethServer) GetBlockByHash(id any, fullTx bool) (map[string]any, error) {
+func (e *ethServer) GetBlockByHash(ctx context.Context, id any, fullTx bool) (map[string]any, error) {
+	ctx, cancel := e.timeouts.context(ctx, "eth_getBlockByHash")
+	defer cancel()
+
 	e.logger.Info("trying: GetBlockByHash", "id", id)
 
 	hash := common.Hash{}
@@ -112,22 +203,39 @@ func (e *ethServer) GetBlockByHash(id any, fullTx bool) (map[string]any, error)
 	default:
 		e.logger.Error("invalid type for id", "id", id)
 	}
-	compositeBlock := e.blockStore.GetCompositeBlock(hash)
+	compositeBlock, err := e.resolveCompositeBlock(hash)
+	if errors.Is(err, store.ErrNotFound) {
+		// Unknown blocks are returned as null, same as geth does.
+		e.logger.Info("completed: GetBlockByHash, unknown block", "hash", hash)
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
 
 	var gethResult map[string]any
-	err := e.ethRPC.CallContext(context.TODO(), &gethResult, "eth_getBlockByHash", compositeBlock.GethHash, fullTx)
+	err = e.ethRPC.CallContext(ctx, &gethResult, "eth_getBlockByHash", compositeBlock.GethHash, fullTx)
 	if err != nil {
 		e.logger.Error("failed to call geth", "error", err)
 		return nil, err
 	}
 
+	if gethResult == nil {
+		// The geth block may have been reorged out since the composite block was stored.
+		e.logger.Info("completed: GetBlockByHash, unknown geth block", "hash", compositeBlock.GethHash)
+		return nil, nil
+	}
+
 	// NOTE: Do we even need to do forwarding? We don't use this block currently.
 	var abciResult map[string]any
-	err = e.peptideRPC.CallContext(context.TODO(), &abciResult, "eth_getBlockByHash", compositeBlock.ABCIHash, fullTx)
+	err = e.peptideRPC.CallContext(ctx, &abciResult, "eth_getBlockByHash", compositeBlock.ABCIHash, fullTx)
 	if err != nil {
 		e.logger.Error("failed to call abci", "error", err)
 		return nil, err
 	}
+	if abciResult == nil {
+		e.logger.Info("completed: GetBlockByHash, unknown abci block", "hash", compositeBlock.ABCIHash)
+		return nil, nil
+	}
 
 	gethResult["hash"] = compositeBlock.Hash()
 
@@ -135,14 +243,32 @@ func (e *ethServer) GetBlockByHash(id any, fullTx bool) (map[string]any, error)
 	return gethResult, err
 }
 
+// resolveCompositeBlock returns the composite block for the given hash, which may either be a
+// composite hash or the hash of the geth or abci block it contains.
+func (e *ethServer) resolveCompositeBlock(hash common.Hash) (eetypes.CompositeBlock, error) {
+	compositeBlock, err := e.blockStore.GetCompositeBlock(hash)
+	if !errors.Is(err, store.ErrNotFound) {
+		return compositeBlock, err
+	}
+	compositeBlock, err = e.blockStore.GetCompositeBlockByGethHash(hash)
+	if !errors.Is(err, store.ErrNotFound) {
+		return compositeBlock, err
+	}
+
+	return e.blockStore.GetCompositeBlockByABCIHash(hash)
+}
+
 // --- Pass through methods, required for intercepting 'sendRawTransaction'. We don't need to do anything special here.
 
 // Added for completeness -- tests do not appear to invoke for time being.
-func (e *ethServer) GetProof(address common.Address, storageKeys []string, blockNrOrHash rpc.BlockNumberOrHash) (map[string]any, error) {
+func (e *ethServer) GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNrOrHash rpc.BlockNumberOrHash) (map[string]any, error) {
+	ctx, cancel := e.timeouts.context(ctx, "eth_getProof")
+	defer cancel()
+
 	e.logger.Info("trying: GetProof")
 
 	var result map[string]any
-	err := e.ethRPC.CallContext(context.TODO(), &result, "eth_getProof", address, storageKeys, blockNrOrHash)
+	err := e.ethRPC.CallContext(ctx, &result, "eth_getProof", address, storageKeys, blockNrOrHash)
 
 	e.logger.Info("completed: GetProof", "result", result)
 	return result, err
@@ -150,40 +276,52 @@ func (e *ethServer) GetProof(address common.Address, storageKeys []string, block
 
 // Added for completeness -- tests do not appear to invoke for time being.
 // GetTransactionReceipt returns the transaction receipt for the given transaction hash.
-func (e *ethServer) GetTransactionReceipt(txHash common.Hash) (map[string]any, error) {
+func (e *ethServer) GetTransactionReceipt(ctx context.Context, txHash common.Hash) (map[string]any, error) {
+	ctx, cancel := e.timeouts.context(ctx, "eth_getTransactionReceipt")
+	defer cancel()
+
 	e.logger.Info("trying: GetTransactionReceipt")
 	var result map[string]any
-	err := e.ethRPC.CallContext(context.TODO(), &result, "eth_getTransactionReceipt", txHash)
+	err := e.ethRPC.CallContext(ctx, &result, "eth_getTransactionReceipt", txHash)
 
 	e.logger.Info("completed: GetTransactionReceipt", "error", err, "result", result)
 	return result, err
 }
 
-func (e *ethServer) MaxPriorityFeePerGas() (hexutil.Big, error) {
+func (e *ethServer) MaxPriorityFeePerGas(ctx context.Context) (hexutil.Big, error) {
+	ctx, cancel := e.timeouts.context(ctx, "eth_maxPriorityFeePerGas")
+	defer cancel()
+
 	e.logger.Info("trying: MaxPriorityFeePerGas")
 
 	var result hexutil.Big
-	err := e.ethRPC.CallContext(context.TODO(), &result, "eth_maxPriorityFeePerGas")
+	err := e.ethRPC.CallContext(ctx, &result, "eth_maxPriorityFeePerGas")
 
 	e.logger.Info("completed: MaxPriorityFeePerGas", "result", result, "error", err)
 	return result, err
 }
 
-func (e *ethServer) GetCode(address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+func (e *ethServer) GetCode(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	ctx, cancel := e.timeouts.context(ctx, "eth_getCode")
+	defer cancel()
+
 	e.logger.Info("trying: GetCode")
 
 	var result hexutil.Bytes
-	err := e.ethRPC.CallContext(context.TODO(), &result, "eth_getCode", address, blockNrOrHash)
+	err := e.ethRPC.CallContext(ctx, &result, "eth_getCode", address, blockNrOrHash)
 
 	e.logger.Info("completed: GetCode", "result", result, "error", err)
 	return result, err
 }
 
-func (e *ethServer) EstimateGas(msg any) (hexutil.Uint64, error) {
+func (e *ethServer) EstimateGas(ctx context.Context, msg any) (hexutil.Uint64, error) {
+	ctx, cancel := e.timeouts.context(ctx, "eth_estimateGas")
+	defer cancel()
+
 	e.logger.Info("trying: EstimateGas")
 
 	var result hexutil.Uint64
-	err := e.ethRPC.CallContext(context.TODO(), &result, "eth_estimateGas", msg)
+	err := e.ethRPC.CallContext(ctx, &result, "eth_estimateGas", msg)
 	if err != nil {
 		return 0, err
 	}
@@ -192,21 +330,27 @@ func (e *ethServer) EstimateGas(msg any) (hexutil.Uint64, error) {
 	return result, nil
 }
 
-func (e *ethServer) GetTransactionCount(address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+func (e *ethServer) GetTransactionCount(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+	ctx, cancel := e.timeouts.context(ctx, "eth_getTransactionCount")
+	defer cancel()
+
 	e.logger.Info("trying: GetTransactionCount")
 
 	var result hexutil.Uint64
-	err := e.ethRPC.CallContext(context.TODO(), &result, "eth_getTransactionCount", address, blockNrOrHash)
+	err := e.ethRPC.CallContext(ctx, &result, "eth_getTransactionCount", address, blockNrOrHash)
 
 	e.logger.Info("completed: GetTransactionCount", "result", result, "error", err)
 	return result, err
 }
 
-func (e *ethServer) Call(msg any, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+func (e *ethServer) Call(ctx context.Context, msg any, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	ctx, cancel := e.timeouts.context(ctx, "eth_call")
+	defer cancel()
+
 	e.logger.Info("trying: Call")
 
 	var result hexutil.Bytes
-	err := e.ethRPC.CallContext(context.TODO(), &result, "eth_call", msg, blockNrOrHash)
+	err := e.ethRPC.CallContext(ctx, &result, "eth_call", msg, blockNrOrHash)
 
 	e.logger.Info("completed: Call", "result", result, "error", err)
 	return result, err
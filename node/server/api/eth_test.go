@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/cometbft/cometbft/libs/log"
+
+	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
+)
+
+func TestGetBlockByNumber(t *testing.T) {
+	block := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x02"))
+	gethBlock := map[string]any{"hash": block.GethHash.Hex(), "number": "0xa"}
+	abciBlock := map[string]any{"hash": block.ABCIHash.Hex(), "number": "0xa"}
+
+	testCases := []struct {
+		name       string
+		geth       any
+		abci       any
+		abciErr    error
+		expErr     bool
+		expUnknown bool
+	}{
+		{"success", gethBlock, abciBlock, nil, false, false},
+		{"unknown geth block", nil, abciBlock, nil, false, true},
+		{"abci engine fails", gethBlock, nil, errors.New("connection refused"), true, false},
+		{"unknown abci block", gethBlock, nil, nil, true, false},
+		{"missing geth block number", map[string]any{"hash": block.GethHash.Hex()}, abciBlock, nil, true, false},
+		{"invalid abci block hash", gethBlock, map[string]any{"hash": 1, "number": "0xa"}, nil, true, false},
+		{"abci block at another height", gethBlock, map[string]any{"hash": block.ABCIHash.Hex(), "number": "0x9"}, nil, true, false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			interceptor := mockInterceptor{newMockMempool(), newMockBlockStore(), mockPayloadStore{}}
+			ethRPC := &mockRPC{handle: func(_ string, args ...any) (any, error) {
+				require.Equal(t, "latest", args[0])
+				return tc.geth, nil
+			}}
+			// peptide is asked for the height geth resolved the tag to.
+			peptideRPC := &mockRPC{handle: func(_ string, args ...any) (any, error) {
+				require.Equal(t, hexutil.Uint64(10), args[0])
+				return tc.abci, tc.abciErr
+			}}
+			server := newEthAPI(interceptor, ethRPC, peptideRPC, Timeouts{}, log.NewNopLogger())
+
+			result, err := server.GetBlockByNumber(context.Background(), "latest", false)
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tc.expUnknown {
+				require.Nil(t, result)
+				return
+			}
+
+			require.Equal(t, block.Hash(), result["hash"])
+			saved, err := interceptor.GetCompositeBlock(block.Hash())
+			require.NoError(t, err)
+			require.Equal(t, block, saved)
+		})
+	}
+}
+
+func TestGetBlockByHash(t *testing.T) {
+	block := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x02"))
+	gethBlock := map[string]any{"hash": block.GethHash.Hex(), "number": "0xa"}
+	abciBlock := map[string]any{"hash": block.ABCIHash.Hex(), "number": "0xa"}
+
+	testCases := []struct {
+		name       string
+		hash       common.Hash
+		geth       any
+		abci       any
+		expUnknown bool
+	}{
+		{"success", block.Hash(), gethBlock, abciBlock, false},
+		{"success: geth hash", block.GethHash, gethBlock, abciBlock, false},
+		{"unknown composite block", common.HexToHash("0xff"), gethBlock, abciBlock, true},
+		{"geth block reorged out", block.Hash(), nil, abciBlock, true},
+		{"abci block unknown", block.Hash(), gethBlock, nil, true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			interceptor := mockInterceptor{newMockMempool(), newMockBlockStore(block), mockPayloadStore{}}
+			ethRPC := &mockRPC{handle: func(_ string, args ...any) (any, error) {
+				require.Equal(t, block.GethHash, args[0])
+				return tc.geth, nil
+			}}
+			peptideRPC := &mockRPC{handle: func(_ string, args ...any) (any, error) {
+				require.Equal(t, block.ABCIHash, args[0])
+				return tc.abci, nil
+			}}
+			server := newEthAPI(interceptor, ethRPC, peptideRPC, Timeouts{}, log.NewNopLogger())
+
+			result, err := server.GetBlockByHash(context.Background(), tc.hash.Hex(), false)
+			require.NoError(t, err)
+			if tc.expUnknown {
+				require.Nil(t, result)
+				return
+			}
+			require.Equal(t, block.Hash(), result["hash"])
+		})
+	}
+}
+
+func TestSendRawTransaction(t *testing.T) {
+	testCases := []struct {
+		name    string
+		gethErr error
+	}{
+		{"success", nil},
+		{"rejected by geth", mockRPCError{}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			data := hexutil.Bytes{0x01, 0x02}
+			var forwarded []hexutil.Bytes
+			ethRPC := &mockRPC{handle: func(method string, args ...any) (any, error) {
+				require.Equal(t, "eth_sendRawTransaction", method)
+				forwarded = append(forwarded, args[0].(hexutil.Bytes))
+				return common.HexToHash("0xaa"), tc.gethErr
+			}}
+			interceptor := mockInterceptor{newMockMempool(), newMockBlockStore(), mockPayloadStore{}}
+			server := newEthAPI(interceptor, ethRPC, nil, Timeouts{}, log.NewNopLogger())
+
+			hash, err := server.SendRawTransaction(context.Background(), data)
+			require.Equal(t, []hexutil.Bytes{data}, forwarded)
+			if tc.gethErr != nil {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, common.HexToHash("0xaa"), hash)
+			}
+			// Bridge messages are only queued once their transactions are part of a payload.
+			require.False(t, interceptor.HasMsgs())
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// gethEngine and abciEngine label the engines in the metrics and errors.
+	gethEngine = "geth"
+	abciEngine = "peptide"
+)
+
+// fanOut runs the independent calls to geth and peptide concurrently and returns their errors once
+// both completed. Both calls share ctx, and so its deadline. The latency of each engine is recorded
+// under method.
+func (e *engineServer) fanOut(ctx context.Context, method string, gethCall, abciCall func(context.Context) error) (gethErr, abciErr error) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		gethErr = e.timeEngineCall(gethEngine, method, func() error { return gethCall(ctx) })
+	}()
+	go func() {
+		defer wg.Done()
+		abciErr = e.timeEngineCall(abciEngine, method, func() error { return abciCall(ctx) })
+	}()
+	wg.Wait()
+
+	return gethErr, abciErr
+}
+
+// timeEngineCall runs the call to the given engine and records its latency under method.
+func (e *engineServer) timeEngineCall(engine, method string, call func() error) error {
+	start := time.Now()
+	err := call()
+	e.metrics.CallDuration.With("engine", engine, "method", method).Observe(time.Since(start).Seconds())
+
+	return err
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cometbft/cometbft/libs/log"
+)
+
+func TestFanOut(t *testing.T) {
+	server := newEngineAPI(nil, nil, nil, nil, NopMetrics(), Timeouts{}, log.NewNopLogger())
+
+	// Each call waits for the other one to start, which only completes if they run concurrently.
+	gethStarted, abciStarted := make(chan struct{}), make(chan struct{})
+	waitFor := func(ctx context.Context, started, other chan struct{}) (time.Time, error) {
+		close(started)
+		deadline, _ := ctx.Deadline()
+		select {
+		case <-other:
+			return deadline, nil
+		case <-time.After(time.Second):
+			return deadline, errors.New("calls did not run concurrently")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var gethDeadline, abciDeadline time.Time
+	abciFailure := errors.New("abci failure")
+	gethErr, abciErr := server.fanOut(ctx, "engine_test",
+		func(ctx context.Context) (err error) {
+			gethDeadline, err = waitFor(ctx, gethStarted, abciStarted)
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			abciDeadline, err = waitFor(ctx, abciStarted, gethStarted)
+			if err != nil {
+				return err
+			}
+			return abciFailure
+		},
+	)
+
+	require.NoError(t, gethErr)
+	require.Equal(t, abciFailure, abciErr)
+	require.False(t, gethDeadline.IsZero())
+	require.Equal(t, gethDeadline, abciDeadline)
+}
@@ -2,20 +2,54 @@ package api
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/ethereum-optimism/optimism/op-service/client"
 
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/cometbft/cometbft/crypto/tmhash"
 	"github.com/cometbft/cometbft/libs/log"
 
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	connectiontypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
 	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	host "github.com/cosmos/ibc-go/v7/modules/core/24-host"
+
+	"github.com/ibc-scouts/ibc-interceptor/node/mempool"
+	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
 )
 
-func GetCosmosAPI(mempoolNode MempoolNode, peptideRPC client.RPC, logger log.Logger) rpc.API {
+const (
+	// ibcDenomPrefix prefixes the ICS-20 voucher denoms.
+	ibcDenomPrefix = "ibc/"
+	// denomTraceQueryMethod is the ICS-20 gRPC query resolving voucher denoms.
+	denomTraceQueryMethod = "/ibc.applications.transfer.v1.Query/DenomTrace"
+	// peptideAddTxMethod runs CheckTx on a cosmos transaction and adds it to the peptide mempool.
+	// Peptide returns the CheckTx result, versions predating it only return whether the
+	// transaction was accepted.
+	peptideAddTxMethod = "intercept_addTxToMempool"
+	// peptideGetTxMethod looks up an included cosmos transaction by hash.
+	peptideGetTxMethod = "intercept_getTransaction"
+	// checkTxErrorCode is the JSON-RPC error code of transactions rejected in CheckTx.
+	checkTxErrorCode = -32003
+	// unknownCheckTxCode is the CheckTx code reported for transactions rejected by peptide
+	// versions that don't return the CheckTx result.
+	unknownCheckTxCode = 1
+)
+
+func GetCosmosAPI(mempoolNode MempoolNode, peptideRPC client.RPC, timeouts Timeouts, logger log.Logger) rpc.API {
 	return rpc.API{
 		Namespace: "cosmos",
-		Service:   newCosmosAPI(mempoolNode, peptideRPC, logger),
+		Service:   newCosmosAPI(mempoolNode, peptideRPC, timeouts, logger),
 	}
 }
 
@@ -23,100 +57,467 @@ func GetCosmosAPI(mempoolNode MempoolNode, peptideRPC client.RPC, logger log.Log
 type cosmosServer struct {
 	mempoolNode MempoolNode
 	peptideRPC  client.RPC
-	logger      log.Logger
+	// timeouts bound the calls forwarded to peptide by each method.
+	timeouts Timeouts
+	logger   log.Logger
 }
 
 // newCosmosAPI returns a new cosmosServer.
-func newCosmosAPI(mempoolNode MempoolNode, peptideRPC client.RPC, logger log.Logger) *cosmosServer {
-	return &cosmosServer{mempoolNode, peptideRPC, logger}
+func newCosmosAPI(mempoolNode MempoolNode, peptideRPC client.RPC, timeouts Timeouts, logger log.Logger) *cosmosServer {
+	return &cosmosServer{mempoolNode, withTimeoutErrors(peptideRPC, abciEngine), timeouts, logger}
 }
 
 /* 'cosmos_' Namespace server methods:
 
 Basically for any information we might want to send over from our e2es.
-Currently we have entrypoints for each of the IBC messages we want to send over, these
-could also be bulk added using a single method.
+Currently we have entrypoints for each of the IBC messages we want to send over: the client and
+connection lifecycle, the channel handshake and packet relaying. These could also be bulk added using a single method.
+The query methods read the IBC state of peptide along with the proofs the counterparty messages need.
 */
 
-func (e *cosmosServer) ChanOpenInit() error {
-	e.logger.Info("trying: ChanOpenInit")
+// CreateClient adds a MsgCreateClient to the mempool and returns its hash.
+func (e *cosmosServer) CreateClient(args CreateClientArgs) (common.Hash, error) {
+	e.logger.Info("trying: CreateClient")
+
+	msg, err := args.Msg()
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return e.enqueueMsg(msg)
+}
+
+// UpdateClient adds a MsgUpdateClient to the mempool and returns its hash.
+func (e *cosmosServer) UpdateClient(args UpdateClientArgs) (common.Hash, error) {
+	e.logger.Info("trying: UpdateClient", "client", args.ClientID)
 
-	// Create a Tx holding a chanopeninit message and add it to the mempool.
-	// When we get a forkchoiceupdate call, we forward it to the peptide app (by another rpc call, not abci).
-	msg := channeltypes.MsgChannelOpenInit{}
-	msgBz, err := msg.Marshal()
+	msg, err := args.Msg()
 	if err != nil {
-		return err
+		return common.Hash{}, err
 	}
 
-	e.mempoolNode.AddMsgToMempool(msgBz)
+	return e.enqueueMsg(msg)
+}
+
+// UpgradeClient adds a MsgUpgradeClient to the mempool and returns its hash.
+func (e *cosmosServer) UpgradeClient(args UpgradeClientArgs) (common.Hash, error) {
+	e.logger.Info("trying: UpgradeClient", "client", args.ClientID)
+
+	msg, err := args.Msg()
+	if err != nil {
+		return common.Hash{}, err
+	}
 
-	return nil
+	return e.enqueueMsg(msg)
 }
 
-func (e *cosmosServer) ChanOpenTry() error {
-	e.logger.Info("trying: ChanOpenTry")
+// SubmitMisbehaviour adds a MsgSubmitMisbehaviour to the mempool and returns its hash.
+func (e *cosmosServer) SubmitMisbehaviour(args SubmitMisbehaviourArgs) (common.Hash, error) {
+	e.logger.Info("trying: SubmitMisbehaviour", "client", args.ClientID)
 
-	// Create a Tx holding a chanopentry message and add it to the mempool.
-	// When we get a forkchoiceupdate call, we forward it to the peptide app (by another rpc call, not abci).
-	msg := channeltypes.MsgChannelOpenTry{}
-	msgBz, err := msg.Marshal()
+	msg, err := args.Msg()
 	if err != nil {
-		return err
+		return common.Hash{}, err
 	}
 
-	e.mempoolNode.AddMsgToMempool(msgBz)
+	return e.enqueueMsg(msg)
+}
+
+// ConnOpenInit adds a MsgConnectionOpenInit to the mempool and returns its hash.
+func (e *cosmosServer) ConnOpenInit(args ConnOpenInitArgs) (common.Hash, error) {
+	e.logger.Info("trying: ConnOpenInit", "client", args.ClientID)
+
+	msg, err := args.Msg()
+	if err != nil {
+		return common.Hash{}, err
+	}
 
-	return nil
+	return e.enqueueMsg(msg)
 }
 
-func (e *cosmosServer) ChanOpenAck() error {
-	e.logger.Info("trying: ChanOpenAck")
+// ConnOpenTry adds a MsgConnectionOpenTry to the mempool and returns its hash.
+func (e *cosmosServer) ConnOpenTry(args ConnOpenTryArgs) (common.Hash, error) {
+	e.logger.Info("trying: ConnOpenTry", "client", args.ClientID, "counterpartyConnection", args.CounterpartyConnectionID)
 
-	// Create a Tx holding a chanopenack message and add it to the mempool.
-	// When we get a forkchoiceupdate call, we forward it to the peptide app (by another rpc call, not abci).
-	msg := channeltypes.MsgChannelOpenAck{}
-	msgBz, err := msg.Marshal()
+	msg, err := args.Msg()
 	if err != nil {
-		return err
+		return common.Hash{}, err
 	}
 
-	e.mempoolNode.AddMsgToMempool(msgBz)
+	return e.enqueueMsg(msg)
+}
+
+// ConnOpenAck adds a MsgConnectionOpenAck to the mempool and returns its hash.
+func (e *cosmosServer) ConnOpenAck(args ConnOpenAckArgs) (common.Hash, error) {
+	e.logger.Info("trying: ConnOpenAck", "connection", args.ConnectionID)
+
+	msg, err := args.Msg()
+	if err != nil {
+		return common.Hash{}, err
+	}
 
-	return nil
+	return e.enqueueMsg(msg)
 }
 
-func (e *cosmosServer) ChanOpenConfirm() error {
-	e.logger.Info("trying: ChanOpenConfirm")
+// ConnOpenConfirm adds a MsgConnectionOpenConfirm to the mempool and returns its hash.
+func (e *cosmosServer) ConnOpenConfirm(args ConnOpenConfirmArgs) (common.Hash, error) {
+	e.logger.Info("trying: ConnOpenConfirm", "connection", args.ConnectionID)
 
-	// Create a Tx holding a chanopenconfirm message and add it to the mempool.
-	// When we get a forkchoiceupdate call, we forward it to the peptide app (by another rpc call, not abci).
-	msg := channeltypes.MsgChannelOpenConfirm{}
-	msgBz, err := msg.Marshal()
+	msg, err := args.Msg()
 	if err != nil {
-		return err
+		return common.Hash{}, err
 	}
 
-	e.mempoolNode.AddMsgToMempool(msgBz)
+	return e.enqueueMsg(msg)
+}
+
+// ChanOpenInit adds a MsgChannelOpenInit to the mempool and returns its hash.
+// When we get a forkchoiceupdate call, we forward it to the peptide app (by another rpc call, not abci).
+func (e *cosmosServer) ChanOpenInit(args ChanOpenInitArgs) (common.Hash, error) {
+	e.logger.Info("trying: ChanOpenInit", "port", args.PortID)
+
+	msg, err := args.Msg()
+	if err != nil {
+		return common.Hash{}, err
+	}
 
-	return nil
+	return e.enqueueMsg(msg)
 }
 
-// SendCosmosTx receives an opaque tx byte slice and adds it to the mempool.
-func (e *cosmosServer) SendTransaction(tx []byte) (SendCosmosTxResult, error) {
-	e.logger.Info("trying: SendTransaction", "tx", tx)
+// ChanOpenTry adds a MsgChannelOpenTry to the mempool and returns its hash.
+func (e *cosmosServer) ChanOpenTry(args ChanOpenTryArgs) (common.Hash, error) {
+	e.logger.Info("trying: ChanOpenTry", "port", args.PortID, "counterpartyChannel", args.CounterpartyChannelID)
 
-	// Try and parse it as a cm and dump it in our own mempool. When we get a forkchoiceupdate
-	// call, we forward it to the peptide app (by another rpc call, not abci).
+	msg, err := args.Msg()
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return e.enqueueMsg(msg)
+}
+
+// ChanOpenAck adds a MsgChannelOpenAck to the mempool and returns its hash.
+func (e *cosmosServer) ChanOpenAck(args ChanOpenAckArgs) (common.Hash, error) {
+	e.logger.Info("trying: ChanOpenAck", "port", args.PortID, "channel", args.ChannelID)
 
-	var result bool
-	err := e.peptideRPC.CallContext(context.TODO(), &result, "intercept_addTxToMempool", tx)
+	msg, err := args.Msg()
 	if err != nil {
-		e.logger.Error("forward SendTransaction to abci engine", "error", err)
+		return common.Hash{}, err
+	}
+
+	return e.enqueueMsg(msg)
+}
+
+// ChanOpenConfirm adds a MsgChannelOpenConfirm to the mempool and returns its hash.
+func (e *cosmosServer) ChanOpenConfirm(args ChanOpenConfirmArgs) (common.Hash, error) {
+	e.logger.Info("trying: ChanOpenConfirm", "port", args.PortID, "channel", args.ChannelID)
+
+	msg, err := args.Msg()
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return e.enqueueMsg(msg)
+}
+
+// RecvPacket adds a MsgRecvPacket to the mempool and returns its hash.
+func (e *cosmosServer) RecvPacket(args RecvPacketArgs) (common.Hash, error) {
+	e.logger.Info("trying: RecvPacket", "channel", args.Packet.SourceChannel, "sequence", args.Packet.Sequence)
+
+	msg, err := args.Msg()
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return e.enqueueMsg(msg)
+}
+
+// AcknowledgePacket adds a MsgAcknowledgement to the mempool and returns its hash.
+func (e *cosmosServer) AcknowledgePacket(args AcknowledgePacketArgs) (common.Hash, error) {
+	e.logger.Info("trying: AcknowledgePacket", "channel", args.Packet.SourceChannel, "sequence", args.Packet.Sequence)
+
+	msg, err := args.Msg()
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return e.enqueueMsg(msg)
+}
+
+// TimeoutPacket adds a MsgTimeout to the mempool and returns its hash.
+func (e *cosmosServer) TimeoutPacket(args TimeoutPacketArgs) (common.Hash, error) {
+	e.logger.Info("trying: TimeoutPacket", "channel", args.Packet.SourceChannel, "sequence", args.Packet.Sequence)
+
+	msg, err := args.Msg()
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return e.enqueueMsg(msg)
+}
+
+// TimeoutOnClose adds a MsgTimeoutOnClose to the mempool and returns its hash.
+func (e *cosmosServer) TimeoutOnClose(args TimeoutOnCloseArgs) (common.Hash, error) {
+	e.logger.Info("trying: TimeoutOnClose", "channel", args.Packet.SourceChannel, "sequence", args.Packet.Sequence)
+
+	msg, err := args.Msg()
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return e.enqueueMsg(msg)
+}
+
+// Transfer adds an ICS-20 MsgTransfer to the mempool and returns its hash.
+func (e *cosmosServer) Transfer(args TransferArgs) (common.Hash, error) {
+	e.logger.Info("trying: Transfer", "channel", args.SourceChannel, "denom", args.Denom, "amount", args.Amount)
+
+	msg, err := args.Msg()
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return e.enqueueMsg(msg)
+}
+
+// DenomTrace returns the denomination trace of an ICS-20 denom. Voucher denoms, "ibc/{hash}", are
+// resolved by querying peptide, full denom paths such as "transfer/channel-0/uatom" are parsed.
+func (e *cosmosServer) DenomTrace(ctx context.Context, denom string) (*DenomTraceResult, error) {
+	ctx, cancel := e.timeouts.context(ctx, "cosmos_denomTrace")
+	defer cancel()
+
+	e.logger.Info("trying: DenomTrace", "denom", denom)
+
+	var trace transfertypes.DenomTrace
+	if strings.HasPrefix(denom, ibcDenomPrefix) {
+		var resp transfertypes.QueryDenomTraceResponse
+		if err := e.grpcQuery(ctx, denomTraceQueryMethod, &transfertypes.QueryDenomTraceRequest{Hash: denom}, &resp); err != nil {
+			return nil, err
+		}
+		if resp.DenomTrace == nil {
+			return nil, fmt.Errorf("denomination trace not found for %s", denom)
+		}
+		trace = *resp.DenomTrace
+	} else {
+		trace = transfertypes.ParseDenomTrace(denom)
+	}
+
+	if err := trace.Validate(); err != nil {
+		return nil, err
 	}
 
-	e.logger.Info("success in forwarding SendTransaction to abci engine", "result", result)
+	e.logger.Info("completed: DenomTrace", "path", trace.Path, "baseDenom", trace.BaseDenom)
+	return &DenomTraceResult{Path: trace.Path, BaseDenom: trace.BaseDenom, IBCDenom: trace.IBCDenom()}, nil
+}
+
+// AbciQuery runs an ABCI query against peptide at the given height, zero being the latest one.
+// With prove set, raw store queries such as "store/ibc/key" also return the merkle proof of the
+// value.
+func (e *cosmosServer) AbciQuery(ctx context.Context, path string, data hexutil.Bytes, height int64, prove bool) (*ABCIQueryResult, error) {
+	ctx, cancel := e.timeouts.context(ctx, "cosmos_abciQuery")
+	defer cancel()
+
+	e.logger.Info("trying: AbciQuery", "path", path, "height", height, "prove", prove)
+
+	result, err := e.abciQuery(ctx, path, data, height, prove)
+	if err != nil {
+		return nil, err
+	}
+
+	e.logger.Info("completed: AbciQuery", "path", path, "height", result.Height)
+	return result, nil
+}
+
+// QueryChannel returns the channel end along with its proof, as needed by the counterparty
+// handshake messages.
+func (e *cosmosServer) QueryChannel(ctx context.Context, portID, channelID string, height int64) (*ChannelResult, error) {
+	ctx, cancel := e.timeouts.context(ctx, "cosmos_queryChannel")
+	defer cancel()
+
+	e.logger.Info("trying: QueryChannel", "port", portID, "channel", channelID, "height", height)
+
+	var channel channeltypes.Channel
+	proven, found, err := e.provenQuery(ctx, host.ChannelKey(portID, channelID), height, &channel)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ChannelResult{ProvenQueryResult: proven}
+	if found {
+		result.Channel = &channel
+	}
+
+	e.logger.Info("completed: QueryChannel", "found", found, "height", proven.Height)
+	return result, nil
+}
+
+// QueryConnection returns the connection end along with its proof, as needed by the
+// counterparty handshake messages.
+func (e *cosmosServer) QueryConnection(ctx context.Context, connectionID string, height int64) (*ConnectionResult, error) {
+	ctx, cancel := e.timeouts.context(ctx, "cosmos_queryConnection")
+	defer cancel()
+
+	e.logger.Info("trying: QueryConnection", "connection", connectionID, "height", height)
+
+	var connection connectiontypes.ConnectionEnd
+	proven, found, err := e.provenQuery(ctx, host.ConnectionKey(connectionID), height, &connection)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ConnectionResult{ProvenQueryResult: proven}
+	if found {
+		result.Connection = &connection
+	}
+
+	e.logger.Info("completed: QueryConnection", "found", found, "height", proven.Height)
+	return result, nil
+}
+
+// QueryClientState returns the packed client state along with its proof, as needed by the
+// connection handshake messages.
+func (e *cosmosServer) QueryClientState(ctx context.Context, clientID string, height int64) (*ClientStateResult, error) {
+	ctx, cancel := e.timeouts.context(ctx, "cosmos_queryClientState")
+	defer cancel()
+
+	e.logger.Info("trying: QueryClientState", "client", clientID, "height", height)
+
+	var clientState codectypes.Any
+	proven, found, err := e.provenQuery(ctx, host.FullClientStateKey(clientID), height, &clientState)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ClientStateResult{ProvenQueryResult: proven}
+	if found {
+		result.ClientState = &Any{TypeURL: clientState.TypeUrl, Value: clientState.Value}
+	}
+
+	e.logger.Info("completed: QueryClientState", "found", found, "height", proven.Height)
+	return result, nil
+}
+
+// QueryPacketCommitment returns the commitment of a sent packet along with its proof, as needed
+// by MsgRecvPacket. Proves its absence once the packet has been acknowledged or timed out.
+func (e *cosmosServer) QueryPacketCommitment(ctx context.Context, portID, channelID string, sequence uint64, height int64) (*PacketCommitmentResult, error) {
+	ctx, cancel := e.timeouts.context(ctx, "cosmos_queryPacketCommitment")
+	defer cancel()
+
+	e.logger.Info("trying: QueryPacketCommitment", "port", portID, "channel", channelID, "sequence", sequence, "height", height)
+
+	proven, found, err := e.provenQuery(ctx, host.PacketCommitmentKey(portID, channelID, sequence), height, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PacketCommitmentResult{ProvenQueryResult: proven}
+	if found {
+		result.Commitment = proven.Value
+	}
+
+	e.logger.Info("completed: QueryPacketCommitment", "found", found, "height", proven.Height)
+	return result, nil
+}
+
+// MempoolStatus returns the messages waiting in the interceptor mempool, in the order they are
+// forwarded to peptide.
+func (e *cosmosServer) MempoolStatus() mempool.Status {
+	e.logger.Info("trying: MempoolStatus")
+
+	return e.mempoolNode.MempoolStatus()
+}
+
+// enqueueMsg validates the message and adds it to the mempool. Returns the id of its mempool
+// envelope.
+func (e *cosmosServer) enqueueMsg(msg sdk.Msg) (common.Hash, error) {
+	// Fill in the cached values of the Anys held by the message, ValidateBasic relies on them.
+	if err := codectypes.UnpackInterfaces(msg, ibcInterfaceRegistry); err != nil {
+		e.logger.Error("failed to unpack message", "type", sdk.MsgTypeURL(msg), "error", err)
+		return common.Hash{}, err
+	}
+
+	if err := msg.ValidateBasic(); err != nil {
+		e.logger.Error("invalid message", "type", sdk.MsgTypeURL(msg), "error", err)
+		return common.Hash{}, err
+	}
+
+	envelope, err := eetypes.NewMsgEnvelope(msg, time.Now())
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	if err := e.mempoolNode.AddMsgToMempool(envelope); err != nil {
+		e.logger.Error("failed to queue message", "type", envelope.TypeURL, "id", envelope.ID, "error", err)
+		return common.Hash{}, err
+	}
+
+	e.logger.Info("queued message", "type", envelope.TypeURL, "id", envelope.ID)
+	return envelope.ID, nil
+}
+
+// SendTransaction forwards an encoded cosmos transaction to the peptide mempool. Returns its
+// CometBFT hash and CheckTx result, or a CheckTxError if peptide rejects it.
+func (e *cosmosServer) SendTransaction(ctx context.Context, tx []byte) (*SendCosmosTxResult, error) {
+	ctx, cancel := e.timeouts.context(ctx, "cosmos_sendTransaction")
+	defer cancel()
+
+	e.logger.Info("trying: SendTransaction", "size", len(tx))
+
+	var response json.RawMessage
+	err := e.peptideRPC.CallContext(ctx, &response, peptideAddTxMethod, tx)
+	if err != nil {
+		e.logger.Error("failed to forward SendTransaction to abci engine", "error", err)
+		return nil, err
+	}
+	result, err := decodeAddTxResult(response)
+	if err != nil {
+		e.logger.Error("invalid SendTransaction result from abci engine", "error", err)
+		return nil, err
+	}
+	result.Hash = tmhash.Sum(tx)
+
+	if result.Code != 0 {
+		e.logger.Info("abci engine rejected transaction", "hash", result.Hash, "code", result.Code, "log", result.Log)
+		return nil, &CheckTxError{Result: &result}
+	}
+
+	e.logger.Info("completed: SendTransaction", "hash", result.Hash, "gasWanted", result.GasWanted)
+	return &result, nil
+}
+
+// decodeAddTxResult decodes the response of peptideAddTxMethod, either a CheckTx result or, for
+// older peptide versions, whether the transaction was accepted.
+func decodeAddTxResult(response json.RawMessage) (SendCosmosTxResult, error) {
+	switch strings.TrimSpace(string(response)) {
+	case "true":
+		return SendCosmosTxResult{}, nil
+	case "false":
+		return SendCosmosTxResult{Code: unknownCheckTxCode, Log: "rejected by abci engine"}, nil
+	}
+
+	var result *SendCosmosTxResult
+	if err := json.Unmarshal(response, &result); err != nil {
+		return SendCosmosTxResult{}, fmt.Errorf("unexpected %s result: %w", peptideAddTxMethod, err)
+	} else if result == nil {
+		return SendCosmosTxResult{}, fmt.Errorf("empty %s result", peptideAddTxMethod)
+	}
+	return *result, nil
+}
+
+// GetTransaction returns the cosmos transaction with the given CometBFT hash once included in a
+// block, nil if unknown or still pending.
+func (e *cosmosServer) GetTransaction(ctx context.Context, hash hexutil.Bytes) (*CosmosTxResult, error) {
+	ctx, cancel := e.timeouts.context(ctx, "cosmos_getTransaction")
+	defer cancel()
+
+	e.logger.Info("trying: GetTransaction", "hash", hash)
+
+	var result *CosmosTxResult
+	if err := e.peptideRPC.CallContext(ctx, &result, peptideGetTxMethod, hash); err != nil {
+		e.logger.Error("failed to forward GetTransaction to abci engine", "error", err)
+		return nil, err
+	}
 
-	e.logger.Info("completed: SendTransaction")
-	return SendCosmosTxResult{}, nil
+	e.logger.Info("completed: GetTransaction", "hash", hash, "found", result != nil)
+	return result, nil
 }
@@ -0,0 +1,572 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cosmos/gogoproto/proto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/ethereum-optimism/optimism/op-service/client"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/cometbft/cometbft/crypto/tmhash"
+	"github.com/cometbft/cometbft/libs/log"
+
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	connectiontypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
+	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v7/modules/core/23-commitment/types"
+	ibctm "github.com/cosmos/ibc-go/v7/modules/light-clients/07-tendermint"
+
+	"github.com/ibc-scouts/ibc-interceptor/node/mempool"
+	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
+)
+
+// mockMempool is a MempoolNode backed by a mempool with the default limits.
+type mockMempool struct {
+	pool *mempool.Mempool
+}
+
+var _ MempoolNode = (*mockMempool)(nil)
+
+func newMockMempool() *mockMempool {
+	return &mockMempool{mempool.NewMempool(mempool.Config{}, mempool.NopMetrics())}
+}
+
+func (m *mockMempool) HasMsgs() bool                                  { return m.pool.Has() }
+func (m *mockMempool) GetMsgs() []*eetypes.MsgEnvelope                { return m.pool.Msgs() }
+func (m *mockMempool) TakeMsgs() []*eetypes.MsgEnvelope               { return m.pool.Take() }
+func (m *mockMempool) AddMsgToMempool(msg *eetypes.MsgEnvelope) error { return m.pool.Add(msg) }
+func (m *mockMempool) MempoolStatus() mempool.Status                  { return m.pool.Status() }
+
+func (m *mockMempool) ReportFailedMsg(msg *eetypes.MsgEnvelope, reason error) {
+	m.pool.ReportFailure(msg, reason)
+}
+
+func (m *mockMempool) RequeueMsgs(msgs []*eetypes.MsgEnvelope) []*eetypes.MsgEnvelope {
+	return m.pool.Requeue(msgs)
+}
+
+var testSigner = sdk.AccAddress(make([]byte, 20)).String()
+
+func TestChanOpenInit(t *testing.T) {
+	validArgs := func() ChanOpenInitArgs {
+		return ChanOpenInitArgs{
+			PortID:             "transfer",
+			Version:            "ics20-1",
+			Order:              "UNORDERED",
+			ConnectionHops:     []string{"connection-0"},
+			CounterpartyPortID: "transfer",
+			Signer:             testSigner,
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		malleate func(*ChanOpenInitArgs)
+		expErr   bool
+	}{
+		{"success", func(*ChanOpenInitArgs) {}, false},
+		{"success: prefixed order", func(a *ChanOpenInitArgs) { a.Order = "ORDER_ORDERED" }, false},
+		{"failure: invalid order", func(a *ChanOpenInitArgs) { a.Order = "NONE" }, true},
+		{"failure: invalid port", func(a *ChanOpenInitArgs) { a.PortID = "" }, true},
+		{"failure: no connection hops", func(a *ChanOpenInitArgs) { a.ConnectionHops = nil }, true},
+		{"failure: invalid signer", func(a *ChanOpenInitArgs) { a.Signer = "signer" }, true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mempool := newMockMempool()
+			server := newCosmosAPI(mempool, nil, Timeouts{}, log.NewNopLogger())
+
+			args := validArgs()
+			tc.malleate(&args)
+
+			hash, err := server.ChanOpenInit(args)
+			if tc.expErr {
+				require.Error(t, err)
+				require.False(t, mempool.HasMsgs())
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, mempool.GetMsgs(), 1)
+			require.Equal(t, mempool.GetMsgs()[0].ID, hash)
+
+			require.Equal(t, "/ibc.core.channel.v1.MsgChannelOpenInit", mempool.GetMsgs()[0].TypeURL)
+			require.Equal(t, args.Signer, mempool.GetMsgs()[0].Sender)
+
+			var msg channeltypes.MsgChannelOpenInit
+			require.NoError(t, msg.Unmarshal(mempool.GetMsgs()[0].Value))
+			require.Equal(t, args.PortID, msg.PortId)
+			require.Equal(t, args.Signer, msg.Signer)
+		})
+	}
+}
+
+func TestChanOpenHandshake(t *testing.T) {
+	mempool := newMockMempool()
+	server := newCosmosAPI(mempool, nil, Timeouts{}, log.NewNopLogger())
+	proofHeight := Height{RevisionNumber: 0, RevisionHeight: 10}
+
+	_, err := server.ChanOpenTry(ChanOpenTryArgs{
+		PortID: "transfer", Version: "ics20-1", Order: "UNORDERED", ConnectionHops: []string{"connection-0"},
+		CounterpartyPortID: "transfer", CounterpartyChannelID: "channel-0", CounterpartyVersion: "ics20-1",
+		ProofInit: []byte("proof"), ProofHeight: proofHeight, Signer: testSigner,
+	})
+	require.NoError(t, err)
+
+	_, err = server.ChanOpenAck(ChanOpenAckArgs{
+		PortID: "transfer", ChannelID: "channel-0", CounterpartyChannelID: "channel-1", CounterpartyVersion: "ics20-1",
+		ProofTry: []byte("proof"), ProofHeight: proofHeight, Signer: testSigner,
+	})
+	require.NoError(t, err)
+
+	_, err = server.ChanOpenConfirm(ChanOpenConfirmArgs{
+		PortID: "transfer", ChannelID: "channel-0", ProofAck: []byte("proof"), ProofHeight: proofHeight, Signer: testSigner,
+	})
+	require.NoError(t, err)
+
+	// Proofs are required.
+	_, err = server.ChanOpenConfirm(ChanOpenConfirmArgs{PortID: "transfer", ChannelID: "channel-0", ProofHeight: proofHeight, Signer: testSigner})
+	require.Error(t, err)
+
+	require.Len(t, mempool.GetMsgs(), 3)
+}
+
+// marshalAny returns msg as a JSON friendly Any.
+func marshalAny(t *testing.T, msg proto.Message) *Any {
+	t.Helper()
+
+	protoAny, err := codectypes.NewAnyWithValue(msg)
+	require.NoError(t, err)
+	return &Any{TypeURL: protoAny.TypeUrl, Value: protoAny.Value}
+}
+
+func TestCreateClient(t *testing.T) {
+	height := clienttypes.NewHeight(0, 10)
+	clientState := marshalAny(t, ibctm.NewClientState(
+		"chain-0", ibctm.DefaultTrustLevel, time.Hour, 2*time.Hour, time.Minute,
+		height, commitmenttypes.GetSDKSpecs(), nil,
+	))
+	consensusState := marshalAny(t, ibctm.NewConsensusState(
+		time.Now(), commitmenttypes.NewMerkleRoot([]byte("root")), make([]byte, 32),
+	))
+
+	testCases := []struct {
+		name     string
+		malleate func(*CreateClientArgs)
+		expErr   bool
+	}{
+		{"success", func(*CreateClientArgs) {}, false},
+		{"failure: no client state", func(a *CreateClientArgs) { a.ClientState = nil }, true},
+		{"failure: unregistered client state type", func(a *CreateClientArgs) { a.ClientState = &Any{TypeURL: "/unknown.ClientState"} }, true},
+		{"failure: mismatched consensus state", func(a *CreateClientArgs) { a.ConsensusState = clientState }, true},
+		{"failure: invalid signer", func(a *CreateClientArgs) { a.Signer = "" }, true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mempool := newMockMempool()
+			server := newCosmosAPI(mempool, nil, Timeouts{}, log.NewNopLogger())
+
+			args := CreateClientArgs{ClientState: clientState, ConsensusState: consensusState, Signer: testSigner}
+			tc.malleate(&args)
+
+			_, err := server.CreateClient(args)
+			if tc.expErr {
+				require.Error(t, err)
+				require.False(t, mempool.HasMsgs())
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, mempool.GetMsgs(), 1)
+		})
+	}
+}
+
+func TestConnOpenInit(t *testing.T) {
+	mempool := newMockMempool()
+	server := newCosmosAPI(mempool, nil, Timeouts{}, log.NewNopLogger())
+
+	_, err := server.ConnOpenInit(ConnOpenInitArgs{
+		ClientID:             "07-tendermint-0",
+		CounterpartyClientID: "07-tendermint-1",
+		Version:              &ConnectionVersion{Identifier: "1", Features: []string{"ORDER_ORDERED", "ORDER_UNORDERED"}},
+		Signer:               testSigner,
+	})
+	require.NoError(t, err)
+	require.Len(t, mempool.GetMsgs(), 1)
+
+	// The counterparty prefix defaults to the ibc store key.
+	var msg connectiontypes.MsgConnectionOpenInit
+	require.NoError(t, msg.Unmarshal(mempool.GetMsgs()[0].Value))
+	require.Equal(t, []byte("ibc"), msg.Counterparty.Prefix.KeyPrefix)
+
+	_, err = server.ConnOpenInit(ConnOpenInitArgs{ClientID: "07-tendermint-0", Signer: testSigner})
+	require.Error(t, err, "counterparty client id is required")
+	require.Len(t, mempool.GetMsgs(), 1)
+}
+
+func TestPacketRelay(t *testing.T) {
+	packet := Packet{
+		Sequence:           1,
+		SourcePort:         "transfer",
+		SourceChannel:      "channel-0",
+		DestinationPort:    "transfer",
+		DestinationChannel: "channel-1",
+		Data:               []byte("data"),
+		TimeoutHeight:      Height{RevisionNumber: 0, RevisionHeight: 100},
+	}
+	proof := []byte("proof")
+	proofHeight := Height{RevisionNumber: 0, RevisionHeight: 10}
+
+	invalidPacket := packet
+	invalidPacket.Sequence = 0
+
+	testCases := []struct {
+		name   string
+		relay  func(*cosmosServer) (common.Hash, error)
+		expErr bool
+	}{
+		{
+			"success: recv packet",
+			func(s *cosmosServer) (common.Hash, error) {
+				return s.RecvPacket(RecvPacketArgs{packet, proof, proofHeight, testSigner})
+			},
+			false,
+		},
+		{
+			"success: acknowledge packet",
+			func(s *cosmosServer) (common.Hash, error) {
+				return s.AcknowledgePacket(AcknowledgePacketArgs{packet, []byte("ack"), proof, proofHeight, testSigner})
+			},
+			false,
+		},
+		{
+			"success: timeout packet",
+			func(s *cosmosServer) (common.Hash, error) {
+				return s.TimeoutPacket(TimeoutPacketArgs{packet, 1, proof, proofHeight, testSigner})
+			},
+			false,
+		},
+		{
+			"success: timeout on close",
+			func(s *cosmosServer) (common.Hash, error) {
+				return s.TimeoutOnClose(TimeoutOnCloseArgs{packet, 1, proof, proof, proofHeight, testSigner})
+			},
+			false,
+		},
+		{
+			"failure: invalid packet",
+			func(s *cosmosServer) (common.Hash, error) {
+				return s.RecvPacket(RecvPacketArgs{invalidPacket, proof, proofHeight, testSigner})
+			},
+			true,
+		},
+		{
+			"failure: empty acknowledgement",
+			func(s *cosmosServer) (common.Hash, error) {
+				return s.AcknowledgePacket(AcknowledgePacketArgs{packet, nil, proof, proofHeight, testSigner})
+			},
+			true,
+		},
+		{
+			"failure: no close proof",
+			func(s *cosmosServer) (common.Hash, error) {
+				return s.TimeoutOnClose(TimeoutOnCloseArgs{packet, 1, proof, nil, proofHeight, testSigner})
+			},
+			true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mempool := newMockMempool()
+
+			hash, err := tc.relay(newCosmosAPI(mempool, nil, Timeouts{}, log.NewNopLogger()))
+			if tc.expErr {
+				require.Error(t, err)
+				require.False(t, mempool.HasMsgs())
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, mempool.GetMsgs(), 1)
+			require.Equal(t, mempool.GetMsgs()[0].ID, hash)
+		})
+	}
+}
+
+// mockRPC is a client.RPC answering calls with handle, responses are JSON round tripped into the
+// call results like they are by a real client.
+type mockRPC struct {
+	handle func(method string, args ...any) (any, error)
+}
+
+var _ client.RPC = (*mockRPC)(nil)
+
+func (m *mockRPC) Close() {}
+
+func (m *mockRPC) CallContext(_ context.Context, result any, method string, args ...any) error {
+	resp, err := m.handle(method, args...)
+	if err != nil || result == nil {
+		return err
+	}
+
+	bz, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bz, result)
+}
+
+func (m *mockRPC) BatchCallContext(context.Context, []rpc.BatchElem) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockRPC) EthSubscribe(context.Context, any, ...any) (ethereum.Subscription, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestTransfer(t *testing.T) {
+	validArgs := func() TransferArgs {
+		return TransferArgs{
+			SourcePort:    "transfer",
+			SourceChannel: "channel-0",
+			Denom:         "stake",
+			Amount:        "100000000000000000000000",
+			Sender:        testSigner,
+			Receiver:      "receiver",
+			TimeoutHeight: Height{RevisionNumber: 0, RevisionHeight: 100},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		malleate func(*TransferArgs)
+		expErr   bool
+	}{
+		{"success", func(*TransferArgs) {}, false},
+		{"success: timeout timestamp", func(a *TransferArgs) { a.TimeoutHeight, a.TimeoutTimestamp = Height{}, 1 }, false},
+		{"failure: invalid amount", func(a *TransferArgs) { a.Amount = "1.5" }, true},
+		{"failure: zero amount", func(a *TransferArgs) { a.Amount = "0" }, true},
+		{"failure: invalid denom", func(a *TransferArgs) { a.Denom = "1" }, true},
+		{"failure: no timeout", func(a *TransferArgs) { a.TimeoutHeight = Height{} }, true},
+		{"failure: no receiver", func(a *TransferArgs) { a.Receiver = "" }, true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mempool := newMockMempool()
+			server := newCosmosAPI(mempool, nil, Timeouts{}, log.NewNopLogger())
+
+			args := validArgs()
+			tc.malleate(&args)
+
+			_, err := server.Transfer(args)
+			if tc.expErr {
+				require.Error(t, err)
+				require.False(t, mempool.HasMsgs())
+				return
+			}
+			require.NoError(t, err)
+
+			var msg transfertypes.MsgTransfer
+			require.NoError(t, msg.Unmarshal(mempool.GetMsgs()[0].Value))
+			require.Equal(t, args.Amount, msg.Token.Amount.String())
+		})
+	}
+}
+
+func TestDenomTrace(t *testing.T) {
+	trace := transfertypes.ParseDenomTrace("transfer/channel-0/uatom")
+
+	testCases := []struct {
+		name     string
+		denom    string
+		response any
+		expTrace *DenomTraceResult
+		expErr   bool
+	}{
+		{
+			"success: native denom",
+			"uatom",
+			nil,
+			&DenomTraceResult{BaseDenom: "uatom", IBCDenom: "uatom"},
+			false,
+		},
+		{
+			"success: denom path",
+			"transfer/channel-0/uatom",
+			nil,
+			&DenomTraceResult{Path: "transfer/channel-0", BaseDenom: "uatom", IBCDenom: trace.IBCDenom()},
+			false,
+		},
+		{
+			"success: voucher denom",
+			trace.IBCDenom(),
+			ABCIQueryResult{Value: marshalProto(t, &transfertypes.QueryDenomTraceResponse{DenomTrace: &trace})},
+			&DenomTraceResult{Path: "transfer/channel-0", BaseDenom: "uatom", IBCDenom: trace.IBCDenom()},
+			false,
+		},
+		{
+			"failure: unknown voucher denom",
+			trace.IBCDenom(),
+			ABCIQueryResult{Code: 1, Log: "denomination trace not found"},
+			nil,
+			true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			peptideRPC := &mockRPC{handle: func(method string, args ...any) (any, error) {
+				require.Equal(t, peptideABCIQueryMethod, method)
+				require.Equal(t, denomTraceQueryMethod, args[0])
+				return tc.response, nil
+			}}
+			server := newCosmosAPI(newMockMempool(), peptideRPC, Timeouts{}, log.NewNopLogger())
+
+			result, err := server.DenomTrace(context.Background(), tc.denom)
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expTrace, result)
+		})
+	}
+}
+
+func marshalProto(t *testing.T, msg proto.Message) []byte {
+	t.Helper()
+
+	bz, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	return bz
+}
+
+func TestSendTransaction(t *testing.T) {
+	tx := []byte("tx")
+	hash := hexutil.Bytes(tmhash.Sum(tx))
+
+	testCases := []struct {
+		name      string
+		response  any
+		rpcErr    error
+		expResult *SendCosmosTxResult
+		expErr    error
+	}{
+		{
+			"success",
+			SendCosmosTxResult{GasWanted: 200000, GasUsed: 50000},
+			nil,
+			&SendCosmosTxResult{Hash: hash, GasWanted: 200000, GasUsed: 50000},
+			nil,
+		},
+		{
+			"failure: rejected in CheckTx",
+			SendCosmosTxResult{Code: 5, Codespace: "sdk", Log: "insufficient funds"},
+			nil,
+			nil,
+			&CheckTxError{Result: &SendCosmosTxResult{Hash: hash, Code: 5, Codespace: "sdk", Log: "insufficient funds"}},
+		},
+		{
+			"success: accepted by a peptide version returning a bool",
+			true,
+			nil,
+			&SendCosmosTxResult{Hash: hash},
+			nil,
+		},
+		{
+			"failure: rejected by a peptide version returning a bool",
+			false,
+			nil,
+			nil,
+			&CheckTxError{Result: &SendCosmosTxResult{Hash: hash, Code: unknownCheckTxCode, Log: "rejected by abci engine"}},
+		},
+		{
+			"failure: abci engine unreachable",
+			nil,
+			errors.New("connection refused"),
+			nil,
+			errors.New("connection refused"),
+		},
+		{
+			"failure: empty result",
+			nil,
+			nil,
+			nil,
+			fmt.Errorf("empty %s result", peptideAddTxMethod),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			peptideRPC := &mockRPC{handle: func(method string, args ...any) (any, error) {
+				require.Equal(t, peptideAddTxMethod, method)
+				require.Equal(t, []any{tx}, args)
+				return tc.response, tc.rpcErr
+			}}
+			server := newCosmosAPI(newMockMempool(), peptideRPC, Timeouts{}, log.NewNopLogger())
+
+			result, err := server.SendTransaction(context.Background(), tx)
+			require.Equal(t, tc.expErr, err)
+			require.Equal(t, tc.expResult, result)
+
+			var dataErr rpc.DataError
+			if errors.As(err, &dataErr) {
+				require.Equal(t, checkTxErrorCode, err.(rpc.Error).ErrorCode())
+				require.Equal(t, tc.expErr.(*CheckTxError).Result, dataErr.ErrorData())
+			}
+		})
+	}
+}
+
+func TestGetTransaction(t *testing.T) {
+	hash := hexutil.Bytes(tmhash.Sum([]byte("tx")))
+	included := &CosmosTxResult{Hash: hash, Height: 12, Index: 1, GasWanted: 200000, GasUsed: 50000, Tx: []byte("tx")}
+
+	testCases := []struct {
+		name      string
+		response  any
+		expResult *CosmosTxResult
+	}{
+		{"success", included, included},
+		{"success: unknown transaction", nil, nil},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			peptideRPC := &mockRPC{handle: func(method string, args ...any) (any, error) {
+				require.Equal(t, peptideGetTxMethod, method)
+				require.Equal(t, []any{hash}, args)
+				return tc.response, nil
+			}}
+			server := newCosmosAPI(newMockMempool(), peptideRPC, Timeouts{}, log.NewNopLogger())
+
+			result, err := server.GetTransaction(context.Background(), hash)
+			require.NoError(t, err)
+			require.Equal(t, tc.expResult, result)
+		})
+	}
+}
@@ -0,0 +1,467 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	sdkmath "cosmossdk.io/math"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	transfertypes "github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	connectiontypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
+	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v7/modules/core/23-commitment/types"
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+)
+
+/* JSON parameters of the 'cosmos_' IBC methods.
+
+Each of them builds the ibc-go message it stands for, the messages are validated with ValidateBasic
+before they are added to the mempool. Proofs are hex encoded, signers are bech32 addresses and
+client states, consensus states and client messages are protobuf Anys of the registered light
+client types, see ibcInterfaceRegistry.
+*/
+
+// Height is a JSON friendly ibc-go client height.
+type Height struct {
+	RevisionNumber uint64 `json:"revisionNumber"`
+	RevisionHeight uint64 `json:"revisionHeight"`
+}
+
+// ClientHeight returns the ibc-go client height.
+func (h Height) ClientHeight() clienttypes.Height {
+	return clienttypes.NewHeight(h.RevisionNumber, h.RevisionHeight)
+}
+
+// ChanOpenInitArgs are the parameters of cosmos_chanOpenInit.
+type ChanOpenInitArgs struct {
+	PortID  string `json:"portId"`
+	Version string `json:"version"`
+	// Order is "ORDERED" or "UNORDERED", the ibc-go "ORDER_" prefixed names are accepted too.
+	Order              string   `json:"order"`
+	ConnectionHops     []string `json:"connectionHops"`
+	CounterpartyPortID string   `json:"counterpartyPortId"`
+	Signer             string   `json:"signer"`
+}
+
+// Msg returns the MsgChannelOpenInit.
+func (a ChanOpenInitArgs) Msg() (*channeltypes.MsgChannelOpenInit, error) {
+	order, err := parseOrder(a.Order)
+	if err != nil {
+		return nil, err
+	}
+
+	return channeltypes.NewMsgChannelOpenInit(a.PortID, a.Version, order, a.ConnectionHops, a.CounterpartyPortID, a.Signer), nil
+}
+
+// ChanOpenTryArgs are the parameters of cosmos_chanOpenTry.
+type ChanOpenTryArgs struct {
+	PortID                string        `json:"portId"`
+	Version               string        `json:"version"`
+	Order                 string        `json:"order"`
+	ConnectionHops        []string      `json:"connectionHops"`
+	CounterpartyPortID    string        `json:"counterpartyPortId"`
+	CounterpartyChannelID string        `json:"counterpartyChannelId"`
+	CounterpartyVersion   string        `json:"counterpartyVersion"`
+	ProofInit             hexutil.Bytes `json:"proofInit"`
+	ProofHeight           Height        `json:"proofHeight"`
+	Signer                string        `json:"signer"`
+}
+
+// Msg returns the MsgChannelOpenTry.
+func (a ChanOpenTryArgs) Msg() (*channeltypes.MsgChannelOpenTry, error) {
+	order, err := parseOrder(a.Order)
+	if err != nil {
+		return nil, err
+	}
+
+	return channeltypes.NewMsgChannelOpenTry(
+		a.PortID, a.Version, order, a.ConnectionHops,
+		a.CounterpartyPortID, a.CounterpartyChannelID, a.CounterpartyVersion,
+		a.ProofInit, a.ProofHeight.ClientHeight(), a.Signer,
+	), nil
+}
+
+// ChanOpenAckArgs are the parameters of cosmos_chanOpenAck.
+type ChanOpenAckArgs struct {
+	PortID                string        `json:"portId"`
+	ChannelID             string        `json:"channelId"`
+	CounterpartyChannelID string        `json:"counterpartyChannelId"`
+	CounterpartyVersion   string        `json:"counterpartyVersion"`
+	ProofTry              hexutil.Bytes `json:"proofTry"`
+	ProofHeight           Height        `json:"proofHeight"`
+	Signer                string        `json:"signer"`
+}
+
+// Msg returns the MsgChannelOpenAck.
+func (a ChanOpenAckArgs) Msg() (*channeltypes.MsgChannelOpenAck, error) {
+	return channeltypes.NewMsgChannelOpenAck(
+		a.PortID, a.ChannelID, a.CounterpartyChannelID, a.CounterpartyVersion,
+		a.ProofTry, a.ProofHeight.ClientHeight(), a.Signer,
+	), nil
+}
+
+// ChanOpenConfirmArgs are the parameters of cosmos_chanOpenConfirm.
+type ChanOpenConfirmArgs struct {
+	PortID      string        `json:"portId"`
+	ChannelID   string        `json:"channelId"`
+	ProofAck    hexutil.Bytes `json:"proofAck"`
+	ProofHeight Height        `json:"proofHeight"`
+	Signer      string        `json:"signer"`
+}
+
+// Msg returns the MsgChannelOpenConfirm.
+func (a ChanOpenConfirmArgs) Msg() (*channeltypes.MsgChannelOpenConfirm, error) {
+	return channeltypes.NewMsgChannelOpenConfirm(a.PortID, a.ChannelID, a.ProofAck, a.ProofHeight.ClientHeight(), a.Signer), nil
+}
+
+// parseOrder parses a channel order by its name, with or without the "ORDER_" prefix.
+func parseOrder(name string) (channeltypes.Order, error) {
+	name = strings.ToUpper(name)
+	if !strings.HasPrefix(name, "ORDER_") {
+		name = "ORDER_" + name
+	}
+
+	order, ok := channeltypes.Order_value[name]
+	if !ok || channeltypes.Order(order) == channeltypes.NONE {
+		return channeltypes.NONE, fmt.Errorf("invalid channel order %q", name)
+	}
+
+	return channeltypes.Order(order), nil
+}
+
+// Any is a JSON friendly protobuf Any, its value holds the hex encoded protobuf bytes of the
+// message of the type url, e.g. a "/ibc.lightclients.tendermint.v1.ClientState".
+type Any struct {
+	TypeURL string        `json:"typeUrl"`
+	Value   hexutil.Bytes `json:"value"`
+}
+
+// ProtoAny returns the protobuf Any, nil if no type url is set. Its cached value is filled in by
+// unpacking the message holding it.
+func (a *Any) ProtoAny() *codectypes.Any {
+	if a == nil || a.TypeURL == "" {
+		return nil
+	}
+
+	return &codectypes.Any{TypeUrl: a.TypeURL, Value: a.Value}
+}
+
+// CreateClientArgs are the parameters of cosmos_createClient.
+type CreateClientArgs struct {
+	ClientState    *Any   `json:"clientState"`
+	ConsensusState *Any   `json:"consensusState"`
+	Signer         string `json:"signer"`
+}
+
+// Msg returns the MsgCreateClient.
+func (a CreateClientArgs) Msg() (*clienttypes.MsgCreateClient, error) {
+	return &clienttypes.MsgCreateClient{
+		ClientState:    a.ClientState.ProtoAny(),
+		ConsensusState: a.ConsensusState.ProtoAny(),
+		Signer:         a.Signer,
+	}, nil
+}
+
+// UpdateClientArgs are the parameters of cosmos_updateClient.
+type UpdateClientArgs struct {
+	ClientID string `json:"clientId"`
+	// ClientMessage is a header or misbehaviour of the client type.
+	ClientMessage *Any   `json:"clientMessage"`
+	Signer        string `json:"signer"`
+}
+
+// Msg returns the MsgUpdateClient.
+func (a UpdateClientArgs) Msg() (*clienttypes.MsgUpdateClient, error) {
+	return &clienttypes.MsgUpdateClient{
+		ClientId:      a.ClientID,
+		ClientMessage: a.ClientMessage.ProtoAny(),
+		Signer:        a.Signer,
+	}, nil
+}
+
+// UpgradeClientArgs are the parameters of cosmos_upgradeClient.
+type UpgradeClientArgs struct {
+	ClientID                   string        `json:"clientId"`
+	ClientState                *Any          `json:"clientState"`
+	ConsensusState             *Any          `json:"consensusState"`
+	ProofUpgradeClient         hexutil.Bytes `json:"proofUpgradeClient"`
+	ProofUpgradeConsensusState hexutil.Bytes `json:"proofUpgradeConsensusState"`
+	Signer                     string        `json:"signer"`
+}
+
+// Msg returns the MsgUpgradeClient.
+func (a UpgradeClientArgs) Msg() (*clienttypes.MsgUpgradeClient, error) {
+	return &clienttypes.MsgUpgradeClient{
+		ClientId:                   a.ClientID,
+		ClientState:                a.ClientState.ProtoAny(),
+		ConsensusState:             a.ConsensusState.ProtoAny(),
+		ProofUpgradeClient:         a.ProofUpgradeClient,
+		ProofUpgradeConsensusState: a.ProofUpgradeConsensusState,
+		Signer:                     a.Signer,
+	}, nil
+}
+
+// SubmitMisbehaviourArgs are the parameters of cosmos_submitMisbehaviour.
+type SubmitMisbehaviourArgs struct {
+	ClientID     string `json:"clientId"`
+	Misbehaviour *Any   `json:"misbehaviour"`
+	Signer       string `json:"signer"`
+}
+
+// Msg returns the MsgSubmitMisbehaviour.
+func (a SubmitMisbehaviourArgs) Msg() (*clienttypes.MsgSubmitMisbehaviour, error) {
+	//nolint:staticcheck // ibc-go v7 deprecates the message in favour of MsgUpdateClient but still handles it.
+	return &clienttypes.MsgSubmitMisbehaviour{
+		ClientId:     a.ClientID,
+		Misbehaviour: a.Misbehaviour.ProtoAny(),
+		Signer:       a.Signer,
+	}, nil
+}
+
+// ConnectionVersion is a JSON friendly ibc-go connection version.
+type ConnectionVersion struct {
+	Identifier string   `json:"identifier"`
+	Features   []string `json:"features"`
+}
+
+// connectionVersion returns the ibc-go connection version, nil if v is.
+func (v *ConnectionVersion) connectionVersion() *connectiontypes.Version {
+	if v == nil {
+		return nil
+	}
+
+	return connectiontypes.NewVersion(v.Identifier, v.Features)
+}
+
+// ConnOpenInitArgs are the parameters of cosmos_connOpenInit.
+type ConnOpenInitArgs struct {
+	ClientID             string `json:"clientId"`
+	CounterpartyClientID string `json:"counterpartyClientId"`
+	// CounterpartyPrefix is the commitment prefix of the counterparty, defaults to the ibc store key.
+	CounterpartyPrefix hexutil.Bytes `json:"counterpartyPrefix"`
+	// Version is optional, the counterparty picks one of the supported versions if unset.
+	Version     *ConnectionVersion `json:"version"`
+	DelayPeriod uint64             `json:"delayPeriod"`
+	Signer      string             `json:"signer"`
+}
+
+// Msg returns the MsgConnectionOpenInit.
+func (a ConnOpenInitArgs) Msg() (*connectiontypes.MsgConnectionOpenInit, error) {
+	return connectiontypes.NewMsgConnectionOpenInit(
+		a.ClientID, a.CounterpartyClientID, merklePrefix(a.CounterpartyPrefix),
+		a.Version.connectionVersion(), a.DelayPeriod, a.Signer,
+	), nil
+}
+
+// ConnOpenTryArgs are the parameters of cosmos_connOpenTry.
+type ConnOpenTryArgs struct {
+	ClientID                 string        `json:"clientId"`
+	CounterpartyClientID     string        `json:"counterpartyClientId"`
+	CounterpartyConnectionID string        `json:"counterpartyConnectionId"`
+	CounterpartyPrefix       hexutil.Bytes `json:"counterpartyPrefix"`
+	// ClientState is the client state of this chain held by the counterparty.
+	ClientState          *Any                 `json:"clientState"`
+	CounterpartyVersions []*ConnectionVersion `json:"counterpartyVersions"`
+	DelayPeriod          uint64               `json:"delayPeriod"`
+	ProofInit            hexutil.Bytes        `json:"proofInit"`
+	ProofClient          hexutil.Bytes        `json:"proofClient"`
+	ProofConsensus       hexutil.Bytes        `json:"proofConsensus"`
+	ProofHeight          Height               `json:"proofHeight"`
+	ConsensusHeight      Height               `json:"consensusHeight"`
+	Signer               string               `json:"signer"`
+}
+
+// Msg returns the MsgConnectionOpenTry.
+func (a ConnOpenTryArgs) Msg() (*connectiontypes.MsgConnectionOpenTry, error) {
+	versions := make([]*connectiontypes.Version, len(a.CounterpartyVersions))
+	for i, version := range a.CounterpartyVersions {
+		versions[i] = version.connectionVersion()
+	}
+
+	return &connectiontypes.MsgConnectionOpenTry{
+		ClientId:             a.ClientID,
+		ClientState:          a.ClientState.ProtoAny(),
+		Counterparty:         connectiontypes.NewCounterparty(a.CounterpartyClientID, a.CounterpartyConnectionID, merklePrefix(a.CounterpartyPrefix)),
+		DelayPeriod:          a.DelayPeriod,
+		CounterpartyVersions: versions,
+		ProofHeight:          a.ProofHeight.ClientHeight(),
+		ProofInit:            a.ProofInit,
+		ProofClient:          a.ProofClient,
+		ProofConsensus:       a.ProofConsensus,
+		ConsensusHeight:      a.ConsensusHeight.ClientHeight(),
+		Signer:               a.Signer,
+	}, nil
+}
+
+// ConnOpenAckArgs are the parameters of cosmos_connOpenAck.
+type ConnOpenAckArgs struct {
+	ConnectionID             string             `json:"connectionId"`
+	CounterpartyConnectionID string             `json:"counterpartyConnectionId"`
+	Version                  *ConnectionVersion `json:"version"`
+	// ClientState is the client state of this chain held by the counterparty.
+	ClientState     *Any          `json:"clientState"`
+	ProofTry        hexutil.Bytes `json:"proofTry"`
+	ProofClient     hexutil.Bytes `json:"proofClient"`
+	ProofConsensus  hexutil.Bytes `json:"proofConsensus"`
+	ProofHeight     Height        `json:"proofHeight"`
+	ConsensusHeight Height        `json:"consensusHeight"`
+	Signer          string        `json:"signer"`
+}
+
+// Msg returns the MsgConnectionOpenAck.
+func (a ConnOpenAckArgs) Msg() (*connectiontypes.MsgConnectionOpenAck, error) {
+	return &connectiontypes.MsgConnectionOpenAck{
+		ConnectionId:             a.ConnectionID,
+		CounterpartyConnectionId: a.CounterpartyConnectionID,
+		Version:                  a.Version.connectionVersion(),
+		ClientState:              a.ClientState.ProtoAny(),
+		ProofHeight:              a.ProofHeight.ClientHeight(),
+		ProofTry:                 a.ProofTry,
+		ProofClient:              a.ProofClient,
+		ProofConsensus:           a.ProofConsensus,
+		ConsensusHeight:          a.ConsensusHeight.ClientHeight(),
+		Signer:                   a.Signer,
+	}, nil
+}
+
+// ConnOpenConfirmArgs are the parameters of cosmos_connOpenConfirm.
+type ConnOpenConfirmArgs struct {
+	ConnectionID string        `json:"connectionId"`
+	ProofAck     hexutil.Bytes `json:"proofAck"`
+	ProofHeight  Height        `json:"proofHeight"`
+	Signer       string        `json:"signer"`
+}
+
+// Msg returns the MsgConnectionOpenConfirm.
+func (a ConnOpenConfirmArgs) Msg() (*connectiontypes.MsgConnectionOpenConfirm, error) {
+	return connectiontypes.NewMsgConnectionOpenConfirm(a.ConnectionID, a.ProofAck, a.ProofHeight.ClientHeight(), a.Signer), nil
+}
+
+// merklePrefix returns the commitment prefix, the ibc store key if none is given.
+func merklePrefix(prefix []byte) commitmenttypes.MerklePrefix {
+	if len(prefix) == 0 {
+		prefix = []byte(ibcexported.StoreKey)
+	}
+
+	return commitmenttypes.NewMerklePrefix(prefix)
+}
+
+// Packet is a JSON friendly ibc-go packet, its data is hex encoded.
+type Packet struct {
+	Sequence           uint64        `json:"sequence"`
+	SourcePort         string        `json:"sourcePort"`
+	SourceChannel      string        `json:"sourceChannel"`
+	DestinationPort    string        `json:"destinationPort"`
+	DestinationChannel string        `json:"destinationChannel"`
+	Data               hexutil.Bytes `json:"data"`
+	TimeoutHeight      Height        `json:"timeoutHeight"`
+	TimeoutTimestamp   uint64        `json:"timeoutTimestamp"`
+}
+
+// ChannelPacket returns the ibc-go packet.
+func (p Packet) ChannelPacket() channeltypes.Packet {
+	return channeltypes.NewPacket(
+		p.Data, p.Sequence, p.SourcePort, p.SourceChannel,
+		p.DestinationPort, p.DestinationChannel, p.TimeoutHeight.ClientHeight(), p.TimeoutTimestamp,
+	)
+}
+
+// RecvPacketArgs are the parameters of cosmos_recvPacket.
+type RecvPacketArgs struct {
+	Packet          Packet        `json:"packet"`
+	ProofCommitment hexutil.Bytes `json:"proofCommitment"`
+	ProofHeight     Height        `json:"proofHeight"`
+	Signer          string        `json:"signer"`
+}
+
+// Msg returns the MsgRecvPacket.
+func (a RecvPacketArgs) Msg() (*channeltypes.MsgRecvPacket, error) {
+	return channeltypes.NewMsgRecvPacket(a.Packet.ChannelPacket(), a.ProofCommitment, a.ProofHeight.ClientHeight(), a.Signer), nil
+}
+
+// AcknowledgePacketArgs are the parameters of cosmos_acknowledgePacket.
+type AcknowledgePacketArgs struct {
+	Packet          Packet        `json:"packet"`
+	Acknowledgement hexutil.Bytes `json:"acknowledgement"`
+	ProofAcked      hexutil.Bytes `json:"proofAcked"`
+	ProofHeight     Height        `json:"proofHeight"`
+	Signer          string        `json:"signer"`
+}
+
+// Msg returns the MsgAcknowledgement.
+func (a AcknowledgePacketArgs) Msg() (*channeltypes.MsgAcknowledgement, error) {
+	return channeltypes.NewMsgAcknowledgement(a.Packet.ChannelPacket(), a.Acknowledgement, a.ProofAcked, a.ProofHeight.ClientHeight(), a.Signer), nil
+}
+
+// TimeoutPacketArgs are the parameters of cosmos_timeoutPacket.
+type TimeoutPacketArgs struct {
+	Packet           Packet        `json:"packet"`
+	NextSequenceRecv uint64        `json:"nextSequenceRecv"`
+	ProofUnreceived  hexutil.Bytes `json:"proofUnreceived"`
+	ProofHeight      Height        `json:"proofHeight"`
+	Signer           string        `json:"signer"`
+}
+
+// Msg returns the MsgTimeout.
+func (a TimeoutPacketArgs) Msg() (*channeltypes.MsgTimeout, error) {
+	return channeltypes.NewMsgTimeout(a.Packet.ChannelPacket(), a.NextSequenceRecv, a.ProofUnreceived, a.ProofHeight.ClientHeight(), a.Signer), nil
+}
+
+// TimeoutOnCloseArgs are the parameters of cosmos_timeoutOnClose.
+type TimeoutOnCloseArgs struct {
+	Packet           Packet        `json:"packet"`
+	NextSequenceRecv uint64        `json:"nextSequenceRecv"`
+	ProofUnreceived  hexutil.Bytes `json:"proofUnreceived"`
+	// ProofClose proves the counterparty channel end is closed.
+	ProofClose  hexutil.Bytes `json:"proofClose"`
+	ProofHeight Height        `json:"proofHeight"`
+	Signer      string        `json:"signer"`
+}
+
+// Msg returns the MsgTimeoutOnClose.
+func (a TimeoutOnCloseArgs) Msg() (*channeltypes.MsgTimeoutOnClose, error) {
+	return channeltypes.NewMsgTimeoutOnClose(
+		a.Packet.ChannelPacket(), a.NextSequenceRecv, a.ProofUnreceived, a.ProofClose,
+		a.ProofHeight.ClientHeight(), a.Signer,
+	), nil
+}
+
+// TransferArgs are the parameters of cosmos_transfer.
+type TransferArgs struct {
+	SourcePort    string `json:"sourcePort"`
+	SourceChannel string `json:"sourceChannel"`
+	Denom         string `json:"denom"`
+	// Amount is a base 10 integer string, amounts may exceed 64 bits.
+	Amount   string `json:"amount"`
+	Sender   string `json:"sender"`
+	Receiver string `json:"receiver"`
+	// TimeoutHeight and TimeoutTimestamp are on the destination chain, at least one must be set.
+	TimeoutHeight    Height `json:"timeoutHeight"`
+	TimeoutTimestamp uint64 `json:"timeoutTimestamp"`
+	Memo             string `json:"memo"`
+}
+
+// Msg returns the MsgTransfer.
+func (a TransferArgs) Msg() (*transfertypes.MsgTransfer, error) {
+	amount, ok := sdkmath.NewIntFromString(a.Amount)
+	if !ok {
+		return nil, fmt.Errorf("invalid transfer amount %q", a.Amount)
+	}
+
+	timeoutHeight := a.TimeoutHeight.ClientHeight()
+	if timeoutHeight.IsZero() && a.TimeoutTimestamp == 0 {
+		return nil, errors.New("transfer timeout height or timestamp must be set")
+	}
+
+	// Build the coin directly, sdk.NewCoin panics on invalid denoms which ValidateBasic reports.
+	token := sdk.Coin{Denom: a.Denom, Amount: amount}
+
+	return transfertypes.NewMsgTransfer(a.SourcePort, a.SourceChannel, token, a.Sender, a.Receiver, timeoutHeight, a.TimeoutTimestamp, a.Memo), nil
+}
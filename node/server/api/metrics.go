@@ -0,0 +1,38 @@
+package api
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsSubsystem is the subsystem shared by all metrics exposed by this package.
+const MetricsSubsystem = "engine"
+
+// Metrics contains the metrics exposed by the engine API.
+type Metrics struct {
+	// Duration of the calls forwarded to the engines in seconds, labeled by the engine, "geth" or
+	// "peptide", and the method.
+	CallDuration metrics.Histogram
+}
+
+// PrometheusMetrics returns Metrics registered with the default prometheus registry.
+func PrometheusMetrics(namespace string) *Metrics {
+	return &Metrics{
+		CallDuration: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "call_duration_seconds",
+			Help:      "Duration of the calls forwarded to the engines in seconds.",
+			Buckets:   stdprometheus.DefBuckets,
+		}, []string{"engine", "method"}),
+	}
+}
+
+// NopMetrics returns Metrics that discard all observations.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		CallDuration: discard.NewHistogram(),
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cosmos/gogoproto/proto"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	tmcrypto "github.com/cometbft/cometbft/proto/tendermint/crypto"
+
+	commitmenttypes "github.com/cosmos/ibc-go/v7/modules/core/23-commitment/types"
+	ibcexported "github.com/cosmos/ibc-go/v7/modules/core/exported"
+)
+
+// peptideABCIQueryMethod is the peptide RPC method running an ABCI query against its app.
+const peptideABCIQueryMethod = "intercept_abciQuery"
+
+// ibcStoreQueryPath is the ABCI query path of raw key lookups in the IBC store, the only ones
+// returning proofs.
+var ibcStoreQueryPath = fmt.Sprintf("store/%s/key", ibcexported.StoreKey)
+
+// ProofOp is a JSON friendly merkle proof operation of an ABCI query.
+type ProofOp struct {
+	Type string        `json:"type"`
+	Key  hexutil.Bytes `json:"key"`
+	Data hexutil.Bytes `json:"data"`
+}
+
+// ABCIQueryResult is the response of an ABCI query run by peptide.
+type ABCIQueryResult struct {
+	Code      uint32        `json:"code"`
+	Log       string        `json:"log"`
+	Codespace string        `json:"codespace"`
+	Key       hexutil.Bytes `json:"key"`
+	Value     hexutil.Bytes `json:"value"`
+	// ProofOps prove the value, or its absence, against the app hash of the block after Height.
+	ProofOps []ProofOp `json:"proofOps,omitempty"`
+	Height   int64     `json:"height"`
+}
+
+// MerkleProof returns the ibc-go merkle proof made of the proof operations.
+func (r *ABCIQueryResult) MerkleProof() (commitmenttypes.MerkleProof, error) {
+	ops := &tmcrypto.ProofOps{Ops: make([]tmcrypto.ProofOp, len(r.ProofOps))}
+	for i, op := range r.ProofOps {
+		ops.Ops[i] = tmcrypto.ProofOp{Type: op.Type, Key: op.Key, Data: op.Data}
+	}
+
+	return commitmenttypes.ConvertProofs(ops)
+}
+
+// abciQuery forwards an ABCI query to peptide, a height of zero queries the latest state. Returns
+// an error if the query fails in the app.
+func (e *cosmosServer) abciQuery(ctx context.Context, path string, data []byte, height int64, prove bool) (*ABCIQueryResult, error) {
+	var result ABCIQueryResult
+	err := e.peptideRPC.CallContext(ctx, &result, peptideABCIQueryMethod, path, hexutil.Bytes(data), height, prove)
+	if err != nil {
+		e.logger.Error("failed to forward ABCI query to abci engine", "path", path, "error", err)
+		return nil, err
+	}
+
+	if result.Code != 0 {
+		return nil, fmt.Errorf("ABCI query %s failed with code %d (codespace %q): %s", path, result.Code, result.Codespace, result.Log)
+	}
+
+	return &result, nil
+}
+
+// grpcQuery runs the gRPC query method, e.g. "/ibc.core.channel.v1.Query/Channel", through an ABCI
+// query at the latest height and unmarshals the response into resp.
+func (e *cosmosServer) grpcQuery(ctx context.Context, method string, req, resp proto.Message) error {
+	reqBz, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	result, err := e.abciQuery(ctx, method, reqBz, 0, false)
+	if err != nil {
+		return err
+	}
+
+	return proto.Unmarshal(result.Value, resp)
+}
+
+// provenQuery looks up the key in the IBC store at the given height along with its merkle proof.
+// value is left untouched if the key is absent, the proof then proves its absence.
+func (e *cosmosServer) provenQuery(ctx context.Context, key []byte, height int64, value proto.Message) (*ProvenQueryResult, bool, error) {
+	result, err := e.abciQuery(ctx, ibcStoreQueryPath, key, height, true)
+	if err != nil {
+		return nil, false, err
+	}
+
+	merkleProof, err := result.MerkleProof()
+	if err != nil {
+		return nil, false, err
+	}
+	proof, err := proto.Marshal(&merkleProof)
+	if err != nil {
+		return nil, false, err
+	}
+
+	found := len(result.Value) > 0
+	if found && value != nil {
+		if err := proto.Unmarshal(result.Value, value); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return &ProvenQueryResult{Value: result.Value, Proof: proof, Height: result.Height}, found, nil
+}
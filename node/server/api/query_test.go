@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	ics23 "github.com/cosmos/ics23/go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+
+	"github.com/cometbft/cometbft/libs/log"
+
+	connectiontypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
+	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v7/modules/core/23-commitment/types"
+	host "github.com/cosmos/ibc-go/v7/modules/core/24-host"
+)
+
+// proofOps returns the proof operations of a store query, an IAVL proof of the key followed by
+// the proof of the ibc store in the multistore.
+func proofOps(t *testing.T, key, value []byte) []ProofOp {
+	t.Helper()
+
+	var storeProof *ics23.CommitmentProof
+	if value != nil {
+		storeProof = &ics23.CommitmentProof{Proof: &ics23.CommitmentProof_Exist{Exist: &ics23.ExistenceProof{Key: key, Value: value}}}
+	} else {
+		storeProof = &ics23.CommitmentProof{Proof: &ics23.CommitmentProof_Nonexist{Nonexist: &ics23.NonExistenceProof{Key: key}}}
+	}
+	multistoreProof := &ics23.CommitmentProof{Proof: &ics23.CommitmentProof_Exist{Exist: &ics23.ExistenceProof{Key: []byte("ibc"), Value: []byte("root")}}}
+
+	return []ProofOp{
+		{Type: "ics23:iavl", Key: key, Data: marshalProto(t, storeProof)},
+		{Type: "ics23:simple", Key: []byte("ibc"), Data: marshalProto(t, multistoreProof)},
+	}
+}
+
+// provenQueryRPC mocks peptide answering a proven query of the IBC store with the given value.
+func provenQueryRPC(t *testing.T, key, value []byte, height int64) *mockRPC {
+	t.Helper()
+
+	return &mockRPC{handle: func(method string, args ...any) (any, error) {
+		require.Equal(t, peptideABCIQueryMethod, method)
+		require.Equal(t, []any{ibcStoreQueryPath, hexutil.Bytes(key), height, true}, args)
+		return ABCIQueryResult{Key: key, Value: value, ProofOps: proofOps(t, key, value), Height: height}, nil
+	}}
+}
+
+func TestAbciQuery(t *testing.T) {
+	testCases := []struct {
+		name     string
+		response ABCIQueryResult
+		expErr   bool
+	}{
+		{
+			"success",
+			ABCIQueryResult{Key: []byte("key"), Value: []byte("value"), Height: 10},
+			false,
+		},
+		{
+			"failure: query failed in the app",
+			ABCIQueryResult{Code: 6, Codespace: "sdk", Log: "unknown query path"},
+			true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			peptideRPC := &mockRPC{handle: func(method string, args ...any) (any, error) {
+				require.Equal(t, peptideABCIQueryMethod, method)
+				require.Equal(t, []any{"store/ibc/key", hexutil.Bytes("key"), int64(10), false}, args)
+				return tc.response, nil
+			}}
+			server := newCosmosAPI(newMockMempool(), peptideRPC, Timeouts{}, log.NewNopLogger())
+
+			result, err := server.AbciQuery(context.Background(), "store/ibc/key", []byte("key"), 10, false)
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, &tc.response, result)
+		})
+	}
+}
+
+func TestQueryChannel(t *testing.T) {
+	channel := channeltypes.NewChannel(
+		channeltypes.INIT, channeltypes.UNORDERED, channeltypes.NewCounterparty("transfer", ""), []string{"connection-0"}, "ics20-1",
+	)
+	key := host.ChannelKey("transfer", "channel-0")
+
+	testCases := []struct {
+		name       string
+		value      []byte
+		expChannel *channeltypes.Channel
+	}{
+		{"success", marshalProto(t, &channel), &channel},
+		{"success: channel not found", nil, nil},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			server := newCosmosAPI(newMockMempool(), provenQueryRPC(t, key, tc.value, 10), Timeouts{}, log.NewNopLogger())
+
+			result, err := server.QueryChannel(context.Background(), "transfer", "channel-0", 10)
+			require.NoError(t, err)
+			require.Equal(t, tc.expChannel, result.Channel)
+			require.Equal(t, int64(10), result.Height)
+			require.NotEmpty(t, result.Proof)
+		})
+	}
+}
+
+func TestQueryConnection(t *testing.T) {
+	connection := connectiontypes.NewConnectionEnd(
+		connectiontypes.INIT, "07-tendermint-0", connectiontypes.NewCounterparty("07-tendermint-0", "", merklePrefix(nil)),
+		connectiontypes.ExportedVersionsToProto(connectiontypes.GetCompatibleVersions()), 0,
+	)
+	key := host.ConnectionKey("connection-0")
+
+	server := newCosmosAPI(newMockMempool(), provenQueryRPC(t, key, marshalProto(t, &connection), 0), Timeouts{}, log.NewNopLogger())
+
+	result, err := server.QueryConnection(context.Background(), "connection-0", 0)
+	require.NoError(t, err)
+	require.Equal(t, &connection, result.Connection)
+
+	// The proof decodes into the merkle proof expected by the handshake messages.
+	var merkleProof commitmenttypes.MerkleProof
+	require.NoError(t, merkleProof.Unmarshal(result.Proof))
+	require.Len(t, merkleProof.Proofs, 2)
+}
+
+func TestQueryClientState(t *testing.T) {
+	clientState := &codectypes.Any{TypeUrl: "/ibc.lightclients.tendermint.v1.ClientState", Value: []byte("client state")}
+	key := host.FullClientStateKey("07-tendermint-0")
+
+	server := newCosmosAPI(newMockMempool(), provenQueryRPC(t, key, marshalProto(t, clientState), 5), Timeouts{}, log.NewNopLogger())
+
+	result, err := server.QueryClientState(context.Background(), "07-tendermint-0", 5)
+	require.NoError(t, err)
+	require.Equal(t, &Any{TypeURL: clientState.TypeUrl, Value: clientState.Value}, result.ClientState)
+}
+
+func TestQueryPacketCommitment(t *testing.T) {
+	key := host.PacketCommitmentKey("transfer", "channel-0", 1)
+	commitment := []byte("commitment")
+
+	testCases := []struct {
+		name          string
+		value         []byte
+		expCommitment hexutil.Bytes
+	}{
+		{"success", commitment, commitment},
+		{"success: packet acknowledged", nil, nil},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			server := newCosmosAPI(newMockMempool(), provenQueryRPC(t, key, tc.value, 10), Timeouts{}, log.NewNopLogger())
+
+			result, err := server.QueryPacketCommitment(context.Background(), "transfer", "channel-0", 1, 10)
+			require.NoError(t, err)
+			require.Equal(t, tc.expCommitment, result.Commitment)
+			require.NotEmpty(t, result.Proof)
+		})
+	}
+}
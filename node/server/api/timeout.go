@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/ethereum-optimism/optimism/op-service/client"
+)
+
+const (
+	// DefaultCallTimeout bounds the calls forwarded to the engines by a method without a
+	// configured timeout.
+	DefaultCallTimeout = 10 * time.Second
+
+	// timeoutErrorCode is the JSON-RPC error code returned when an engine did not answer in time,
+	// the "resource unavailable" code of EIP-1474.
+	timeoutErrorCode = -32002
+)
+
+// Timeouts bound the time the served methods may spend forwarding calls to geth and peptide.
+type Timeouts struct {
+	// Default applies to the methods without their own timeout, DefaultCallTimeout if zero.
+	Default time.Duration
+	// Methods holds the timeouts of individual served methods, e.g. "engine_newPayloadV3", and of
+	// the peptideAddMsgMethod calls forwarding each mempool message.
+	Methods map[string]time.Duration
+}
+
+// Of returns the timeout of the served method.
+func (t Timeouts) Of(method string) time.Duration {
+	if timeout, ok := t.Methods[method]; ok && timeout > 0 {
+		return timeout
+	}
+	if t.Default > 0 {
+		return t.Default
+	}
+
+	return DefaultCallTimeout
+}
+
+// context returns the handler context of the served method bounded by its timeout.
+func (t Timeouts) context(ctx context.Context, method string) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, t.Of(method))
+}
+
+// TimeoutError is returned when an engine did not answer a forwarded call before the deadline.
+type TimeoutError struct {
+	// Engine is the engine that did not answer, "geth" or "peptide".
+	Engine string
+	// Method is the method forwarded to the engine.
+	Method string
+}
+
+var _ rpc.Error = (*TimeoutError)(nil)
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out calling %s", e.Engine, e.Method)
+}
+
+// ErrorCode returns the JSON-RPC error code of timed out calls.
+func (e *TimeoutError) ErrorCode() int {
+	return timeoutErrorCode
+}
+
+// timeoutRPC is an engine client reporting the calls that ran out of time as a TimeoutError.
+type timeoutRPC struct {
+	client.RPC
+	engine string
+}
+
+// withTimeoutErrors wraps the client of the given engine, nil clients are left as is.
+func withTimeoutErrors(rpcClient client.RPC, engine string) client.RPC {
+	if rpcClient == nil {
+		return nil
+	}
+
+	return &timeoutRPC{rpcClient, engine}
+}
+
+// CallContext implements client.RPC.
+func (r *timeoutRPC) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	err := r.RPC.CallContext(ctx, result, method, args...)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &TimeoutError{Engine: r.engine, Method: method}
+	}
+
+	return err
+}
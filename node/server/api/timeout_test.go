@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/cometbft/cometbft/libs/log"
+
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+
+	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
+)
+
+// hangingRPC is an engine that never answers, calls return once their context is done.
+type hangingRPC struct {
+	mockRPC
+}
+
+func (m *hangingRPC) CallContext(ctx context.Context, _ any, _ string, _ ...any) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestTimeoutsOf(t *testing.T) {
+	testCases := []struct {
+		name       string
+		timeouts   Timeouts
+		expTimeout time.Duration
+	}{
+		{"default", Timeouts{}, DefaultCallTimeout},
+		{"configured default", Timeouts{Default: time.Second}, time.Second},
+		{
+			"method timeout",
+			Timeouts{Default: time.Second, Methods: map[string]time.Duration{"engine_newPayloadV3": time.Minute}},
+			time.Minute,
+		},
+		{
+			"other method timeout",
+			Timeouts{Default: time.Second, Methods: map[string]time.Duration{"engine_getPayloadV3": time.Minute}},
+			time.Second,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expTimeout, tc.timeouts.Of("engine_newPayloadV3"))
+		})
+	}
+}
+
+func TestForwardedCallTimeout(t *testing.T) {
+	timeouts := Timeouts{Default: time.Minute, Methods: map[string]time.Duration{"cosmos_getTransaction": 10 * time.Millisecond}}
+	server := newCosmosAPI(newMockMempool(), &hangingRPC{}, timeouts, log.NewNopLogger())
+
+	// The method times out on its own deadline.
+	_, err := server.GetTransaction(context.Background(), hexutil.Bytes("hash"))
+	require.Equal(t, &TimeoutError{Engine: abciEngine, Method: peptideGetTxMethod}, err)
+	require.Equal(t, timeoutErrorCode, err.(*TimeoutError).ErrorCode())
+
+	// Cancellations by the caller are propagated to the engine and returned as is.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = server.GetTransaction(ctx, hexutil.Bytes("hash"))
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// partlyHangingRPC is an engine that never answers the calls hang returns true for.
+type partlyHangingRPC struct {
+	mockRPC
+	hang func(method string, args ...any) bool
+}
+
+func (m *partlyHangingRPC) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	if m.hang(method, args...) {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return m.mockRPC.CallContext(ctx, result, method, args...)
+}
+
+func TestForwardMempoolMsgsTimeout(t *testing.T) {
+	signer := sdk.AccAddress(make([]byte, 20)).String()
+	newMsg := func(channel string, arrival time.Duration) *eetypes.MsgEnvelope {
+		msg, err := eetypes.NewMsgEnvelope(channeltypes.NewMsgChannelOpenConfirm("transfer", channel, []byte("proof"), clienttypes.NewHeight(0, 1), signer), time.Now().Add(arrival))
+		require.NoError(t, err)
+		return msg
+	}
+	hung, delivered := newMsg("channel-0", 0), newMsg("channel-1", time.Millisecond)
+
+	var forwarded []*eetypes.MsgEnvelope
+	peptideRPC := &partlyHangingRPC{
+		mockRPC: mockRPC{handle: func(_ string, args ...any) (any, error) {
+			forwarded = append(forwarded, args[0].(*eetypes.MsgEnvelope))
+			return nil, nil
+		}},
+		hang: func(_ string, args ...any) bool { return args[0] == hung },
+	}
+	interceptor := mockInterceptor{newMockMempool(), newMockBlockStore(), mockPayloadStore{}}
+	timeouts := Timeouts{Default: time.Minute, Methods: map[string]time.Duration{peptideAddMsgMethod: 10 * time.Millisecond}}
+	server := newEngineAPI(interceptor, nil, nil, peptideRPC, NopMetrics(), timeouts, log.NewNopLogger())
+
+	// A message peptide doesn't answer in time is requeued without holding up the next ones.
+	require.NoError(t, interceptor.AddMsgToMempool(hung))
+	require.NoError(t, interceptor.AddMsgToMempool(delivered))
+	dropped := server.forwardMempoolMsgs(context.Background())
+	require.Empty(t, dropped)
+	require.Equal(t, []*eetypes.MsgEnvelope{delivered}, forwarded)
+	require.Equal(t, []*eetypes.MsgEnvelope{hung}, interceptor.GetMsgs())
+
+	// Once the update runs out of time the messages are requeued without being sent.
+	forwarded = nil
+	require.NoError(t, interceptor.AddMsgToMempool(delivered))
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	dropped = server.forwardMempoolMsgs(ctx)
+	require.Empty(t, dropped)
+	require.Empty(t, forwarded)
+	require.Equal(t, []*eetypes.MsgEnvelope{hung, delivered}, interceptor.GetMsgs())
+}
@@ -2,10 +2,18 @@
 package api
 
 import (
+	"fmt"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/ethereum-optimism/optimism/op-service/eth"
 
+	connectiontypes "github.com/cosmos/ibc-go/v7/modules/core/03-connection/types"
+	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+
+	"github.com/ibc-scouts/ibc-interceptor/node/mempool"
 	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
 )
 
@@ -23,25 +31,150 @@ type Interceptor interface {
 type MempoolNode interface {
 	// HasMsgs returns true if the mempool has messages.
 	HasMsgs() bool
-	// GetMsgs returns all messages in the mempool.
-	GetMsgs() [][]byte
-	// ClearMsgs clears all messages from the mempool.
-	ClearMsgs()
-	// AddMsgToMempool adds a message to the mempool.
-	AddMsgToMempool(bz []byte)
+	// GetMsgs returns all messages in the mempool without removing them.
+	GetMsgs() []*eetypes.MsgEnvelope
+	// TakeMsgs atomically removes and returns all messages in the mempool, so each message is
+	// handed out once.
+	TakeMsgs() []*eetypes.MsgEnvelope
+	// RequeueMsgs puts taken messages back in the mempool, in their original place. Returns the
+	// messages that expired or no longer fit.
+	RequeueMsgs(msgs []*eetypes.MsgEnvelope) []*eetypes.MsgEnvelope
+	// AddMsgToMempool adds a message to the mempool. Fails for duplicate messages and if the
+	// mempool is full.
+	AddMsgToMempool(msg *eetypes.MsgEnvelope) error
+	// ReportFailedMsg records that a taken message was dropped because peptide rejected it.
+	ReportFailedMsg(msg *eetypes.MsgEnvelope, reason error)
+	// MempoolStatus returns a snapshot of the mempool.
+	MempoolStatus() mempool.Status
 }
 
 // BlockStore allows accessing/modifying/inspecting the compose blocks.
+// Lookups return store.ErrNotFound for unknown hashes or numbers.
 type BlockStore interface {
-	GetCompositeBlock(common.Hash) eetypes.CompositeBlock
-	SaveCompositeBlock(eetypes.CompositeBlock)
+	GetCompositeBlock(common.Hash) (eetypes.CompositeBlock, error)
+	// GetCompositeBlockByGethHash returns the composite block containing the given geth block.
+	GetCompositeBlockByGethHash(common.Hash) (eetypes.CompositeBlock, error)
+	// GetCompositeBlockByABCIHash returns the composite block containing the given abci block.
+	GetCompositeBlockByABCIHash(common.Hash) (eetypes.CompositeBlock, error)
+	// GetCompositeBlockByNumber returns the composite block at the given height.
+	GetCompositeBlockByNumber(uint64) (eetypes.CompositeBlock, error)
+	// CompositeBlockOf returns the stored composite block of the geth and abci blocks, or a new
+	// one hashed with the configured scheme, so that blocks stored under a previous hash scheme
+	// keep their hash. The block is not saved.
+	CompositeBlockOf(gethHash, abciHash common.Hash) (eetypes.CompositeBlock, error)
+	SaveCompositeBlock(eetypes.CompositeBlock) error
+	// SaveCompositeBlockWithNumber saves the composite block and indexes it by its height.
+	SaveCompositeBlockWithNumber(eetypes.CompositeBlock, uint64) error
+	// PruneCompositeBlocks removes composite blocks outside the retention window below the
+	// given finalized composite block.
+	PruneCompositeBlocks(finalized common.Hash) error
+	// SaveCommittedForkchoiceState persists the composite fork choice state both engines
+	// accepted, so that geth can be rolled back to it after a restart.
+	SaveCommittedForkchoiceState(eth.ForkchoiceState) error
+	// CommittedForkchoiceState returns the last committed composite fork choice state.
+	CommittedForkchoiceState() (eth.ForkchoiceState, error)
 }
 
+// PayloadStore allows accessing/modifying the composite payloads of block building jobs.
+// GetCompositePayload returns store.ErrNotFound for unknown or expired payload ids.
 type PayloadStore interface {
-	GetCompositePayload(eth.PayloadID) eetypes.CompositePayload
+	GetCompositePayload(eth.PayloadID) (eetypes.CompositePayload, error)
 	SaveCompositePayload(eetypes.CompositePayload)
+	// DeleteCompositePayload removes a composite payload once it has been served.
+	DeleteCompositePayload(eth.PayloadID)
+}
+
+// SendCosmosTxResult is the CheckTx result of a cosmos transaction accepted by peptide.
+type SendCosmosTxResult struct {
+	// Hash is the CometBFT hash of the transaction, the sha256 of its bytes.
+	Hash      hexutil.Bytes `json:"hash"`
+	Code      uint32        `json:"code"`
+	Log       string        `json:"log"`
+	Codespace string        `json:"codespace"`
+	GasWanted int64         `json:"gasWanted"`
+	GasUsed   int64         `json:"gasUsed"`
+}
+
+// CheckTxError is returned by cosmos_sendTransaction when peptide rejects a transaction in
+// CheckTx. The CheckTx result is sent as the data of the JSON-RPC error.
+type CheckTxError struct {
+	Result *SendCosmosTxResult
+}
+
+var _ rpc.DataError = (*CheckTxError)(nil)
+
+func (e *CheckTxError) Error() string {
+	return fmt.Sprintf("transaction rejected with code %d (codespace %q): %s", e.Result.Code, e.Result.Codespace, e.Result.Log)
+}
+
+// ErrorCode returns the JSON-RPC error code of rejected transactions.
+func (e *CheckTxError) ErrorCode() int {
+	return checkTxErrorCode
+}
+
+// ErrorData returns the CheckTx result.
+func (e *CheckTxError) ErrorData() any {
+	return e.Result
 }
 
-// TODO(jim): Ethereum JSON/RPC dictates responses should either return 0, 1 (response or error) or 2 (response and error).
-// For now, we return 2 just to keep separated.
-type SendCosmosTxResult struct{}
+// CosmosTxResult is a cosmos transaction included in a block, as returned by
+// cosmos_getTransaction.
+type CosmosTxResult struct {
+	Hash   hexutil.Bytes `json:"hash"`
+	Height int64         `json:"height"`
+	// Index is the position of the transaction in its block.
+	Index     uint32        `json:"index"`
+	Code      uint32        `json:"code"`
+	Log       string        `json:"log"`
+	Codespace string        `json:"codespace"`
+	GasWanted int64         `json:"gasWanted"`
+	GasUsed   int64         `json:"gasUsed"`
+	Tx        hexutil.Bytes `json:"tx"`
+}
+
+// DenomTraceResult is the ICS-20 denomination trace of a voucher denom.
+type DenomTraceResult struct {
+	// Path is the chain of port/channel identifiers the token was transferred over.
+	Path      string `json:"path"`
+	BaseDenom string `json:"baseDenom"`
+	// IBCDenom is the "ibc/{hash}" voucher denom, the base denom for native tokens.
+	IBCDenom string `json:"ibcDenom"`
+}
+
+// ProvenQueryResult is a value of the IBC store along with its merkle proof.
+type ProvenQueryResult struct {
+	// Value holds the protobuf bytes of the value, empty if absent.
+	Value hexutil.Bytes `json:"value"`
+	// Proof is the ibc-go MerkleProof of the value, or of its absence.
+	Proof hexutil.Bytes `json:"proof"`
+	// Height is the height the store was queried at, the proof verifies against the app hash
+	// committed by the next block.
+	Height int64 `json:"height"`
+}
+
+// ChannelResult is the response of cosmos_queryChannel, Channel is nil if it doesn't exist.
+type ChannelResult struct {
+	*ProvenQueryResult
+	Channel *channeltypes.Channel `json:"channel"`
+}
+
+// ConnectionResult is the response of cosmos_queryConnection, Connection is nil if it doesn't
+// exist.
+type ConnectionResult struct {
+	*ProvenQueryResult
+	Connection *connectiontypes.ConnectionEnd `json:"connection"`
+}
+
+// ClientStateResult is the response of cosmos_queryClientState, ClientState is nil if the client
+// doesn't exist.
+type ClientStateResult struct {
+	*ProvenQueryResult
+	ClientState *Any `json:"clientState"`
+}
+
+// PacketCommitmentResult is the response of cosmos_queryPacketCommitment, Commitment is empty if
+// the packet was never sent or has been acknowledged.
+type PacketCommitmentResult struct {
+	*ProvenQueryResult
+	Commitment hexutil.Bytes `json:"commitment"`
+}
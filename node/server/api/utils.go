@@ -1,43 +1,108 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/ethereum-optimism/optimism/op-service/eth"
+
+	"github.com/ibc-scouts/ibc-interceptor/node/bridge"
+	"github.com/ibc-scouts/ibc-interceptor/node/store"
+	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
 )
 
-const ibcBridgeAddress = "0x42000000000000000000000000000000000000E1"
+// errUnknownHead is returned by EngineForkStates when the head of the fork choice state is not a
+// known composite block.
+var errUnknownHead = errors.New("unknown head block")
 
 // EngineForkStates takes in the interceptor fork state and the blockstore and creates two states for abci and
-// geth.
-func EngineForkStates(blockStore BlockStore, interceptorForkState eth.ForkchoiceState) (eth.ForkchoiceState, eth.ForkchoiceState) {
-	head, safe, finalized := interceptorForkState.HeadBlockHash, interceptorForkState.SafeBlockHash, interceptorForkState.FinalizedBlockHash
+// geth. Returns errUnknownHead if the head block is unknown and InvalidForkChoiceState if the safe or
+// finalized blocks are. Zero hashes are passed through as is.
+func EngineForkStates(blockStore BlockStore, interceptorForkState eth.ForkchoiceState) (eth.ForkchoiceState, eth.ForkchoiceState, error) {
+	var abci, geth eth.ForkchoiceState
+
+	head, err := lookupForkchoiceBlock(blockStore, interceptorForkState.HeadBlockHash)
+	if errors.Is(err, store.ErrNotFound) {
+		return abci, geth, errUnknownHead
+	} else if err != nil {
+		return abci, geth, err
+	}
+
+	safe, err := lookupForkchoiceBlock(blockStore, interceptorForkState.SafeBlockHash)
+	if errors.Is(err, store.ErrNotFound) {
+		return abci, geth, eetypes.InvalidForkChoiceState.With(fmt.Errorf("unknown safe block %s", interceptorForkState.SafeBlockHash))
+	} else if err != nil {
+		return abci, geth, err
+	}
+
+	finalized, err := lookupForkchoiceBlock(blockStore, interceptorForkState.FinalizedBlockHash)
+	if errors.Is(err, store.ErrNotFound) {
+		return abci, geth, eetypes.InvalidForkChoiceState.With(fmt.Errorf("unknown finalized block %s", interceptorForkState.FinalizedBlockHash))
+	} else if err != nil {
+		return abci, geth, err
+	}
+
+	abci = eth.ForkchoiceState{
+		HeadBlockHash:      head.ABCIHash,
+		SafeBlockHash:      safe.ABCIHash,
+		FinalizedBlockHash: finalized.ABCIHash,
+	}
 
-	abci := eth.ForkchoiceState{
-		HeadBlockHash:      blockStore.GetCompositeBlock(head).ABCIHash,
-		SafeBlockHash:      blockStore.GetCompositeBlock(safe).ABCIHash,
-		FinalizedBlockHash: blockStore.GetCompositeBlock(finalized).ABCIHash,
+	geth = eth.ForkchoiceState{
+		HeadBlockHash:      head.GethHash,
+		SafeBlockHash:      safe.GethHash,
+		FinalizedBlockHash: finalized.GethHash,
 	}
 
-	geth := eth.ForkchoiceState{
-		HeadBlockHash:      blockStore.GetCompositeBlock(head).GethHash,
-		SafeBlockHash:      blockStore.GetCompositeBlock(safe).GethHash,
-		FinalizedBlockHash: blockStore.GetCompositeBlock(finalized).GethHash,
+	return abci, geth, nil
+}
+
+// lookupForkchoiceBlock returns the composite block for a fork choice hash, a zero hash maps to
+// zero hashes for both engines.
+func lookupForkchoiceBlock(blockStore BlockStore, hash common.Hash) (eetypes.CompositeBlock, error) {
+	if hash == eetypes.ZeroHash {
+		return eetypes.CompositeBlock{}, nil
 	}
 
-	return abci, geth
+	return blockStore.GetCompositeBlock(hash)
 }
 
-// IsIBCBridgeTx returns true if the transaction is a transaction sent to the IBCStandardBridge.
-// tx is a hex encoded marshalled types.Transaction (op-geth/core/types/transaction.go)
-func IsIBCBridgeTx(data hexutil.Bytes) bool {
-	tx := new(types.Transaction)
-	if err := tx.UnmarshalBinary(data); err != nil {
-		// This will fail in op-geth if we can't unmarshal so, just
-		// return false here.
-		return false
+// isRetriableError returns true if a call failed before peptide could handle it, e.g. because it
+// is unreachable, timed out or was cancelled. Error responses of peptide itself are rejections
+// that won't succeed when retried.
+func isRetriableError(err error) bool {
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return true
+	}
+
+	var rpcErr rpc.Error
+	return !errors.As(err, &rpcErr)
+}
+
+// IBCBridgeMsg returns the mempool envelope of the cosmos message executing the call of a
+// transaction sent to the IBC bridge, sourced from the transaction hash. Returns false if data is
+// not a bridge transaction and an error if its call is invalid or has no cosmos message (see
+// bridge.ErrUnsupportedCall).
+func IBCBridgeMsg(ibcBridge *bridge.Bridge, data hexutil.Bytes) (*eetypes.MsgEnvelope, bool, error) {
+	msg, isBridgeTx, err := ibcBridge.DecodeMsg(data)
+	if err != nil || !isBridgeTx {
+		return nil, isBridgeTx, err
+	}
+
+	// The hash of a transaction is the keccak256 hash of its binary encoding, for deposits it
+	// commits to their source hash.
+	envelope, err := eetypes.NewSourcedMsgEnvelope(msg, crypto.Keccak256Hash(data), time.Now())
+	if err != nil {
+		return nil, true, err
 	}
 
-	return tx.To().Hex() == ibcBridgeAddress
+	return envelope, true, nil
 }
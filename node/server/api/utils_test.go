@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+
+	"github.com/ibc-scouts/ibc-interceptor/node/store"
+	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
+)
+
+// mockBlockStore is an in-memory BlockStore keyed by composite hash.
+type mockBlockStore struct {
+	blocks map[common.Hash]eetypes.CompositeBlock
+	// numbers indexes the blocks saved with their height.
+	numbers map[uint64]common.Hash
+	// fcs is the committed fork choice state, shared by the copies of the store.
+	fcs *eth.ForkchoiceState
+}
+
+var _ BlockStore = mockBlockStore{}
+
+func newMockBlockStore(blocks ...eetypes.CompositeBlock) mockBlockStore {
+	m := mockBlockStore{
+		blocks:  make(map[common.Hash]eetypes.CompositeBlock),
+		numbers: make(map[uint64]common.Hash),
+		fcs:     new(eth.ForkchoiceState),
+	}
+	for _, block := range blocks {
+		m.blocks[block.Hash()] = block
+	}
+	return m
+}
+
+func (m mockBlockStore) GetCompositeBlock(hash common.Hash) (eetypes.CompositeBlock, error) {
+	block, ok := m.blocks[hash]
+	if !ok {
+		return eetypes.CompositeBlock{}, store.ErrNotFound
+	}
+	return block, nil
+}
+
+func (m mockBlockStore) GetCompositeBlockByGethHash(hash common.Hash) (eetypes.CompositeBlock, error) {
+	for _, block := range m.blocks {
+		if block.GethHash == hash {
+			return block, nil
+		}
+	}
+	return eetypes.CompositeBlock{}, store.ErrNotFound
+}
+
+func (m mockBlockStore) GetCompositeBlockByABCIHash(hash common.Hash) (eetypes.CompositeBlock, error) {
+	for _, block := range m.blocks {
+		if block.ABCIHash == hash {
+			return block, nil
+		}
+	}
+	return eetypes.CompositeBlock{}, store.ErrNotFound
+}
+
+func (m mockBlockStore) GetCompositeBlockByNumber(number uint64) (eetypes.CompositeBlock, error) {
+	hash, ok := m.numbers[number]
+	if !ok {
+		return eetypes.CompositeBlock{}, store.ErrNotFound
+	}
+	return m.GetCompositeBlock(hash)
+}
+
+func (m mockBlockStore) CompositeBlockOf(gethHash, abciHash common.Hash) (eetypes.CompositeBlock, error) {
+	if block, err := m.GetCompositeBlockByGethHash(gethHash); err == nil && block.ABCIHash == abciHash {
+		return block, nil
+	}
+	return eetypes.NewCompositeBlock(gethHash, abciHash), nil
+}
+
+func (m mockBlockStore) SaveCompositeBlock(block eetypes.CompositeBlock) error {
+	m.blocks[block.Hash()] = block
+	return nil
+}
+
+func (m mockBlockStore) SaveCompositeBlockWithNumber(block eetypes.CompositeBlock, number uint64) error {
+	m.numbers[number] = block.Hash()
+	return m.SaveCompositeBlock(block)
+}
+
+func (m mockBlockStore) PruneCompositeBlocks(common.Hash) error {
+	return nil
+}
+
+func (m mockBlockStore) SaveCommittedForkchoiceState(fcs eth.ForkchoiceState) error {
+	*m.fcs = fcs
+	return nil
+}
+
+func (m mockBlockStore) CommittedForkchoiceState() (eth.ForkchoiceState, error) {
+	if *m.fcs == (eth.ForkchoiceState{}) {
+		return eth.ForkchoiceState{}, store.ErrNotFound
+	}
+	return *m.fcs, nil
+}
+
+func TestEngineForkStates(t *testing.T) {
+	head := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x02"))
+	safe := eetypes.NewCompositeBlock(common.HexToHash("0x03"), common.HexToHash("0x04"))
+	unknown := common.HexToHash("0xff")
+
+	testCases := []struct {
+		name      string
+		fcs       eth.ForkchoiceState
+		expAbci   eth.ForkchoiceState
+		expGeth   eth.ForkchoiceState
+		expErr    error
+		expErrMsg string
+	}{
+		{
+			"success: known blocks and zero finalized hash",
+			eth.ForkchoiceState{HeadBlockHash: head.Hash(), SafeBlockHash: safe.Hash()},
+			eth.ForkchoiceState{HeadBlockHash: head.ABCIHash, SafeBlockHash: safe.ABCIHash},
+			eth.ForkchoiceState{HeadBlockHash: head.GethHash, SafeBlockHash: safe.GethHash},
+			nil,
+			"",
+		},
+		{
+			"failure: unknown head",
+			eth.ForkchoiceState{HeadBlockHash: unknown, SafeBlockHash: safe.Hash()},
+			eth.ForkchoiceState{},
+			eth.ForkchoiceState{},
+			errUnknownHead,
+			"",
+		},
+		{
+			"failure: unknown safe block",
+			eth.ForkchoiceState{HeadBlockHash: head.Hash(), SafeBlockHash: unknown},
+			eth.ForkchoiceState{},
+			eth.ForkchoiceState{},
+			nil,
+			"Invalid forkchoice state",
+		},
+		{
+			"failure: unknown finalized block",
+			eth.ForkchoiceState{HeadBlockHash: head.Hash(), SafeBlockHash: safe.Hash(), FinalizedBlockHash: unknown},
+			eth.ForkchoiceState{},
+			eth.ForkchoiceState{},
+			nil,
+			"Invalid forkchoice state",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			abci, geth, err := EngineForkStates(newMockBlockStore(head, safe), tc.fcs)
+			switch {
+			case tc.expErr != nil:
+				require.ErrorIs(t, err, tc.expErr)
+			case tc.expErrMsg != "":
+				require.ErrorContains(t, err, tc.expErrMsg)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tc.expAbci, abci)
+				require.Equal(t, tc.expGeth, geth)
+			}
+		})
+	}
+}
+
+func TestIsRetriableError(t *testing.T) {
+	testCases := []struct {
+		name         string
+		err          error
+		expRetriable bool
+	}{
+		{"unreachable engine", errors.New("connection refused"), true},
+		{"timed out call", &TimeoutError{Engine: abciEngine, Method: peptideAddMsgMethod}, true},
+		{"wrapped timed out call", fmt.Errorf("forwarding: %w", &TimeoutError{Engine: abciEngine, Method: peptideAddMsgMethod}), true},
+		{"deadline exceeded", context.DeadlineExceeded, true},
+		{"cancelled call", context.Canceled, true},
+		{"rejected by the engine", mockRPCError{}, false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expRetriable, isRetriableError(tc.err))
+		})
+	}
+
+	// Calls timed out by their deadline are retriable.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := withTimeoutErrors(&hangingRPC{}, abciEngine).CallContext(ctx, nil, peptideAddMsgMethod)
+	require.Equal(t, &TimeoutError{Engine: abciEngine, Method: peptideAddMsgMethod}, err)
+	require.True(t, isRetriableError(err))
+}
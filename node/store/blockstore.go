@@ -0,0 +1,465 @@
+// Package store holds the durable stores used by the interceptor node.
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+
+	dbm "github.com/cometbft/cometbft-db"
+
+	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
+)
+
+const (
+	// DefaultDBBackend is the backend used when none is configured.
+	DefaultDBBackend = string(dbm.GoLevelDBBackend)
+
+	// blockStoreDBName is the name of the database holding the composite blocks.
+	blockStoreDBName = "blockstore"
+)
+
+// ErrNotFound is returned when a requested entry is not present in a store.
+var ErrNotFound = errors.New("not found")
+
+var (
+	// compositeBlockPrefix prefixes the keys of composite blocks, keyed by their composite hash.
+	compositeBlockPrefix = []byte("cb/")
+	// gethHashPrefix prefixes the index from geth block hashes to composite hashes.
+	gethHashPrefix = []byte("gh/")
+	// abciHashPrefix prefixes the index from abci block hashes to composite hashes.
+	abciHashPrefix = []byte("ah/")
+	// numberPrefix prefixes the index from block numbers to composite hashes.
+	numberPrefix = []byte("bn/")
+	// compositeNumberPrefix prefixes the index from composite hashes to block numbers.
+	compositeNumberPrefix = []byte("cn/")
+	// pruneHeightPrefix prefixes the index of all composite blocks by the height they are pruned
+	// at followed by their composite hash, so that pruning walks every block in height order.
+	pruneHeightPrefix = []byte("ph/")
+	// compositePruneHeightPrefix prefixes the index from composite hashes to prune heights.
+	compositePruneHeightPrefix = []byte("pc/")
+	// forkchoiceStateKey is the key of the last fork choice state both engines committed to.
+	forkchoiceStateKey = []byte("fcs")
+)
+
+// BlockStore persists composite blocks so the mapping from composite hashes to geth and abci
+// hashes survives restarts of the interceptor.
+type BlockStore struct {
+	db      dbm.DB
+	metrics *Metrics
+
+	// mtx guards size, which is kept in sync with the number of stored composite blocks, and
+	// highest, the highest block number saved.
+	mtx     sync.Mutex
+	size    int
+	highest uint64
+}
+
+// NewBlockStore returns a BlockStore backed by the given database.
+func NewBlockStore(db dbm.DB, metrics *Metrics) (*BlockStore, error) {
+	size, err := countKeys(db, compositeBlockPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	highest, err := highestNumber(db)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.CompositeBlocks.Set(float64(size))
+	return &BlockStore{db: db, metrics: metrics, size: size, highest: highest}, nil
+}
+
+// OpenBlockStore opens (or creates) the block store database in dataDir using the given backend.
+// An empty dataDir results in an in-memory store that is lost on shutdown.
+func OpenBlockStore(backend, dataDir string, metrics *Metrics) (*BlockStore, error) {
+	if dataDir == "" {
+		return NewBlockStore(dbm.NewMemDB(), metrics)
+	}
+
+	if backend == "" {
+		backend = DefaultDBBackend
+	}
+
+	db, err := dbm.NewDB(blockStoreDBName, dbm.BackendType(backend), dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open block store in %s: %w", dataDir, err)
+	}
+
+	return NewBlockStore(db, metrics)
+}
+
+// Get returns the composite block with the given composite hash or ErrNotFound.
+func (s *BlockStore) Get(hash common.Hash) (eetypes.CompositeBlock, error) {
+	bz, err := s.db.Get(compositeBlockKey(hash))
+	if err != nil {
+		return eetypes.CompositeBlock{}, err
+	}
+	if bz == nil {
+		return eetypes.CompositeBlock{}, ErrNotFound
+	}
+
+	return eetypes.UnmarshalCompositeBlock(bz)
+}
+
+// GetByGethHash returns the composite block containing the given geth block or ErrNotFound.
+func (s *BlockStore) GetByGethHash(hash common.Hash) (eetypes.CompositeBlock, error) {
+	return s.getIndexed(prefixedKey(gethHashPrefix, hash.Bytes()))
+}
+
+// GetByABCIHash returns the composite block containing the given abci block or ErrNotFound.
+func (s *BlockStore) GetByABCIHash(hash common.Hash) (eetypes.CompositeBlock, error) {
+	return s.getIndexed(prefixedKey(abciHashPrefix, hash.Bytes()))
+}
+
+// GetByNumber returns the composite block at the given height or ErrNotFound.
+func (s *BlockStore) GetByNumber(number uint64) (eetypes.CompositeBlock, error) {
+	return s.getIndexed(numberKey(number))
+}
+
+// NumberOf returns the height the composite block with the given hash was saved at or
+// ErrNotFound if it was never saved with a height.
+func (s *BlockStore) NumberOf(hash common.Hash) (uint64, error) {
+	bz, err := s.db.Get(prefixedKey(compositeNumberPrefix, hash.Bytes()))
+	if err != nil {
+		return 0, err
+	}
+	if bz == nil {
+		return 0, ErrNotFound
+	}
+
+	return binary.BigEndian.Uint64(bz), nil
+}
+
+// Size returns the number of composite blocks in the store.
+func (s *BlockStore) Size() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.size
+}
+
+// Save stores the composite block under its composite hash and indexes it by its geth and
+// abci hashes. Blocks saved without their height are pruned along with the highest block saved
+// so far, unless they are saved with their height later on.
+func (s *BlockStore) Save(block eetypes.CompositeBlock) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	batch := s.db.NewBatch()
+	defer batch.Close()
+
+	if err := writeBlock(batch, block); err != nil {
+		return err
+	}
+	hash := block.Hash()
+	pruneHeight, err := s.db.Get(prefixedKey(compositePruneHeightPrefix, hash.Bytes()))
+	if err != nil {
+		return err
+	}
+	if pruneHeight == nil {
+		if err := writePruneHeight(batch, hash, s.highest); err != nil {
+			return err
+		}
+	}
+
+	return s.commit(batch, block)
+}
+
+// SaveWithNumber is like Save but additionally indexes the block by its height. A later block
+// saved at the same height, e.g. after a reorg, takes over the height index. The previous one is
+// kept, it may still be referenced by the fork choice state, and is left to PruneBelow.
+func (s *BlockStore) SaveWithNumber(block eetypes.CompositeBlock, number uint64) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	batch := s.db.NewBatch()
+	defer batch.Close()
+
+	hash := block.Hash()
+	if err := writeBlock(batch, block); err != nil {
+		return err
+	}
+	// the block may have been saved before without its height, move it to its actual height.
+	pruneHeight, err := s.db.Get(prefixedKey(compositePruneHeightPrefix, hash.Bytes()))
+	if err != nil {
+		return err
+	}
+	if pruneHeight != nil {
+		if err := batch.Delete(pruneHeightKey(binary.BigEndian.Uint64(pruneHeight), hash)); err != nil {
+			return err
+		}
+	}
+	if err := writePruneHeight(batch, hash, number); err != nil {
+		return err
+	}
+	if err := batch.Set(numberKey(number), hash.Bytes()); err != nil {
+		return err
+	}
+	if err := batch.Set(prefixedKey(compositeNumberPrefix, hash.Bytes()), binary.BigEndian.AppendUint64(nil, number)); err != nil {
+		return err
+	}
+
+	if err := s.commit(batch, block); err != nil {
+		return err
+	}
+	s.highest = max(s.highest, number)
+	return nil
+}
+
+// PruneBelow deletes all composite blocks with a height lower than the given one, along with
+// their indexes, and returns the number of blocks removed. Blocks saved without their height are
+// pruned by the height of the highest block saved before them.
+func (s *BlockStore) PruneBelow(number uint64) (int, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	// collect the entries first, writes are not allowed within the domain of an open iterator.
+	it, err := s.db.Iterator(pruneHeightKey(0, common.Hash{}), pruneHeightKey(number, common.Hash{}))
+	if err != nil {
+		return 0, err
+	}
+	var hashes []common.Hash
+	for ; it.Valid(); it.Next() {
+		hashes = append(hashes, common.BytesToHash(it.Key()[len(pruneHeightPrefix)+8:]))
+	}
+	if err := it.Error(); err != nil {
+		it.Close()
+		return 0, err
+	}
+	if err := it.Close(); err != nil {
+		return 0, err
+	}
+
+	batch := s.db.NewBatch()
+	defer batch.Close()
+
+	pruned := 0
+	for _, hash := range hashes {
+		deleted, err := s.deleteBlock(batch, hash)
+		if err != nil {
+			return 0, err
+		}
+		if deleted {
+			pruned++
+		}
+	}
+
+	if err := batch.Write(); err != nil {
+		return 0, err
+	}
+
+	s.size -= pruned
+	s.metrics.CompositeBlocks.Set(float64(s.size))
+	s.metrics.PrunedBlocks.Add(float64(pruned))
+	return pruned, nil
+}
+
+// deleteBlock adds the deletion of the composite block and all its indexes to the batch. Returns
+// false if the block is not stored.
+func (s *BlockStore) deleteBlock(batch dbm.Batch, hash common.Hash) (bool, error) {
+	block, err := s.Get(hash)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	keys := [][]byte{compositeBlockKey(hash), prefixedKey(compositeNumberPrefix, hash.Bytes())}
+
+	pruneHeight, err := s.db.Get(prefixedKey(compositePruneHeightPrefix, hash.Bytes()))
+	if err != nil {
+		return false, err
+	}
+	if pruneHeight != nil {
+		keys = append(keys, prefixedKey(compositePruneHeightPrefix, hash.Bytes()), pruneHeightKey(binary.BigEndian.Uint64(pruneHeight), hash))
+	}
+
+	// the indexes may have been taken over by a newer composite block holding the same geth or
+	// abci block, or at the same height, only drop them if they still point at the deleted one.
+	indexKeys := [][]byte{
+		prefixedKey(gethHashPrefix, block.GethHash.Bytes()),
+		prefixedKey(abciHashPrefix, block.ABCIHash.Bytes()),
+	}
+	number, err := s.db.Get(prefixedKey(compositeNumberPrefix, hash.Bytes()))
+	if err != nil {
+		return false, err
+	}
+	if number != nil {
+		indexKeys = append(indexKeys, numberKey(binary.BigEndian.Uint64(number)))
+	}
+	for _, indexKey := range indexKeys {
+		indexed, err := s.db.Get(indexKey)
+		if err != nil {
+			return false, err
+		}
+		if bytes.Equal(indexed, hash.Bytes()) {
+			keys = append(keys, indexKey)
+		}
+	}
+
+	for _, key := range keys {
+		if err := batch.Delete(key); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// SaveForkchoiceState persists the composite fork choice state both engines committed to,
+// replacing the previous one.
+func (s *BlockStore) SaveForkchoiceState(fcs eth.ForkchoiceState) error {
+	value := make([]byte, 0, 3*common.HashLength)
+	value = append(value, fcs.HeadBlockHash.Bytes()...)
+	value = append(value, fcs.SafeBlockHash.Bytes()...)
+	value = append(value, fcs.FinalizedBlockHash.Bytes()...)
+
+	return s.db.SetSync(forkchoiceStateKey, value)
+}
+
+// ForkchoiceState returns the last saved composite fork choice state, ErrNotFound if none was.
+func (s *BlockStore) ForkchoiceState() (eth.ForkchoiceState, error) {
+	value, err := s.db.Get(forkchoiceStateKey)
+	if err != nil {
+		return eth.ForkchoiceState{}, err
+	}
+	if value == nil {
+		return eth.ForkchoiceState{}, ErrNotFound
+	}
+	if len(value) != 3*common.HashLength {
+		return eth.ForkchoiceState{}, fmt.Errorf("invalid fork choice state of %d bytes", len(value))
+	}
+
+	return eth.ForkchoiceState{
+		HeadBlockHash:      common.BytesToHash(value[:common.HashLength]),
+		SafeBlockHash:      common.BytesToHash(value[common.HashLength : 2*common.HashLength]),
+		FinalizedBlockHash: common.BytesToHash(value[2*common.HashLength:]),
+	}, nil
+}
+
+// Close closes the underlying database.
+func (s *BlockStore) Close() error {
+	return s.db.Close()
+}
+
+// commit writes the batch and accounts for the block if it was not stored before. Must be
+// called with mtx held.
+func (s *BlockStore) commit(batch dbm.Batch, block eetypes.CompositeBlock) error {
+	exists, err := s.db.Has(compositeBlockKey(block.Hash()))
+	if err != nil {
+		return err
+	}
+
+	if err := batch.Write(); err != nil {
+		return err
+	}
+
+	if !exists {
+		s.size++
+		s.metrics.CompositeBlocks.Set(float64(s.size))
+	}
+	return nil
+}
+
+// getIndexed resolves an index key to a composite hash and returns the block stored under it.
+func (s *BlockStore) getIndexed(key []byte) (eetypes.CompositeBlock, error) {
+	bz, err := s.db.Get(key)
+	if err != nil {
+		return eetypes.CompositeBlock{}, err
+	}
+	if bz == nil {
+		return eetypes.CompositeBlock{}, ErrNotFound
+	}
+
+	return s.Get(common.BytesToHash(bz))
+}
+
+// writeBlock adds the block and its hash indexes to the batch. Zero hashes are not indexed as
+// they do not identify a block.
+func writeBlock(batch dbm.Batch, block eetypes.CompositeBlock) error {
+	hash := block.Hash()
+	if err := batch.Set(compositeBlockKey(hash), block.Marshal()); err != nil {
+		return err
+	}
+
+	if block.GethHash != eetypes.ZeroHash {
+		if err := batch.Set(prefixedKey(gethHashPrefix, block.GethHash.Bytes()), hash.Bytes()); err != nil {
+			return err
+		}
+	}
+	if block.ABCIHash != eetypes.ZeroHash {
+		if err := batch.Set(prefixedKey(abciHashPrefix, block.ABCIHash.Bytes()), hash.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writePruneHeight adds the block to the prune height index at the given height.
+func writePruneHeight(batch dbm.Batch, hash common.Hash, height uint64) error {
+	if err := batch.Set(pruneHeightKey(height, hash), []byte{}); err != nil {
+		return err
+	}
+
+	return batch.Set(prefixedKey(compositePruneHeightPrefix, hash.Bytes()), binary.BigEndian.AppendUint64(nil, height))
+}
+
+// highestNumber returns the highest block number in the number index of db, zero if empty.
+func highestNumber(db dbm.DB) (uint64, error) {
+	it, err := dbm.IteratePrefix(db, numberPrefix)
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+
+	// the iterator is ascending, cometbft-db only offers reverse iterators over explicit ranges.
+	var highest uint64
+	for ; it.Valid(); it.Next() {
+		highest = binary.BigEndian.Uint64(it.Key()[len(numberPrefix):])
+	}
+
+	return highest, it.Error()
+}
+
+// countKeys returns the number of keys in db starting with prefix.
+func countKeys(db dbm.DB, prefix []byte) (int, error) {
+	it, err := dbm.IteratePrefix(db, prefix)
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+
+	count := 0
+	for ; it.Valid(); it.Next() {
+		count++
+	}
+
+	return count, it.Error()
+}
+
+func compositeBlockKey(hash common.Hash) []byte {
+	return prefixedKey(compositeBlockPrefix, hash.Bytes())
+}
+
+// numberKey uses big endian encoding so that keys iterate in block order.
+func numberKey(number uint64) []byte {
+	return prefixedKey(numberPrefix, binary.BigEndian.AppendUint64(nil, number))
+}
+
+// pruneHeightKey orders the blocks by height using big endian encoding.
+func pruneHeightKey(height uint64, hash common.Hash) []byte {
+	return append(prefixedKey(pruneHeightPrefix, binary.BigEndian.AppendUint64(nil, height)), hash.Bytes()...)
+}
+
+func prefixedKey(prefix, key []byte) []byte {
+	return append(append([]byte{}, prefix...), key...)
+}
@@ -0,0 +1,241 @@
+package store_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+
+	"github.com/ibc-scouts/ibc-interceptor/node/store"
+	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
+)
+
+func TestBlockStorePersistence(t *testing.T) {
+	dataDir := t.TempDir()
+
+	blockStore, err := store.OpenBlockStore(store.DefaultDBBackend, dataDir, store.NopMetrics())
+	require.NoError(t, err)
+
+	block := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x02"))
+	require.NoError(t, blockStore.Save(block))
+	require.NoError(t, blockStore.Close())
+
+	// reopening the store must return the block saved before closing it.
+	blockStore, err = store.OpenBlockStore(store.DefaultDBBackend, dataDir, store.NopMetrics())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, blockStore.Close()) })
+
+	got, err := blockStore.Get(block.Hash())
+	require.NoError(t, err)
+	require.Equal(t, block, got)
+
+	_, err = blockStore.Get(common.HexToHash("0x03"))
+	require.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func TestBlockStoreForkchoiceState(t *testing.T) {
+	dataDir := t.TempDir()
+
+	blockStore, err := store.OpenBlockStore(store.DefaultDBBackend, dataDir, store.NopMetrics())
+	require.NoError(t, err)
+
+	_, err = blockStore.ForkchoiceState()
+	require.ErrorIs(t, err, store.ErrNotFound)
+
+	fcs := eth.ForkchoiceState{
+		HeadBlockHash:      common.HexToHash("0x01"),
+		SafeBlockHash:      common.HexToHash("0x02"),
+		FinalizedBlockHash: common.HexToHash("0x03"),
+	}
+	require.NoError(t, blockStore.SaveForkchoiceState(eth.ForkchoiceState{HeadBlockHash: common.HexToHash("0xff")}))
+	require.NoError(t, blockStore.SaveForkchoiceState(fcs))
+	require.NoError(t, blockStore.Close())
+
+	// the last committed state survives restarts and isn't counted as a block.
+	blockStore, err = store.OpenBlockStore(store.DefaultDBBackend, dataDir, store.NopMetrics())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, blockStore.Close()) })
+
+	got, err := blockStore.ForkchoiceState()
+	require.NoError(t, err)
+	require.Equal(t, fcs, got)
+	require.Zero(t, blockStore.Size())
+}
+
+func TestBlockStoreMixedHashSchemes(t *testing.T) {
+	blockStore, err := store.OpenBlockStore("", "", store.NopMetrics())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, blockStore.Close()) })
+
+	// A block saved before switching to a versioned scheme keeps its legacy hash.
+	legacy := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x02"))
+	versioned := eetypes.NewCompositeBlockWithScheme(common.HexToHash("0x03"), common.HexToHash("0x04"), eetypes.HashSchemeKeccak256V1)
+	require.NoError(t, blockStore.Save(legacy))
+	require.NoError(t, blockStore.Save(versioned))
+
+	for _, block := range []eetypes.CompositeBlock{legacy, versioned} {
+		got, err := blockStore.Get(block.Hash())
+		require.NoError(t, err)
+		require.Equal(t, block, got)
+
+		got, err = blockStore.GetByGethHash(block.GethHash)
+		require.NoError(t, err)
+		require.Equal(t, block.Hash(), got.Hash())
+	}
+}
+
+func TestBlockStoreIndexes(t *testing.T) {
+	blockStore, err := store.OpenBlockStore("", "", store.NopMetrics())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, blockStore.Close()) })
+
+	block := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x02"))
+	require.NoError(t, blockStore.SaveWithNumber(block, 7))
+
+	got, err := blockStore.GetByGethHash(block.GethHash)
+	require.NoError(t, err)
+	require.Equal(t, block, got)
+
+	got, err = blockStore.GetByABCIHash(block.ABCIHash)
+	require.NoError(t, err)
+	require.Equal(t, block, got)
+
+	got, err = blockStore.GetByNumber(7)
+	require.NoError(t, err)
+	require.Equal(t, block, got)
+
+	// a reorged block at the same height replaces the previous one in the number index.
+	reorged := eetypes.NewCompositeBlock(common.HexToHash("0x03"), common.HexToHash("0x04"))
+	require.NoError(t, blockStore.SaveWithNumber(reorged, 7))
+
+	got, err = blockStore.GetByNumber(7)
+	require.NoError(t, err)
+	require.Equal(t, reorged, got)
+
+	// zero hashes are never indexed.
+	require.NoError(t, blockStore.Save(eetypes.NewCompositeBlock(common.HexToHash("0x05"), common.Hash{})))
+	_, err = blockStore.GetByABCIHash(common.Hash{})
+	require.ErrorIs(t, err, store.ErrNotFound)
+
+	_, err = blockStore.GetByNumber(8)
+	require.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func TestBlockStorePruneBelow(t *testing.T) {
+	blockStore, err := store.OpenBlockStore("", "", store.NopMetrics())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, blockStore.Close()) })
+
+	// blocks without a height are pruned along with the highest block saved before them.
+	early := eetypes.NewCompositeBlock(common.HexToHash("0xee"), common.Hash{})
+	require.NoError(t, blockStore.Save(early))
+
+	var blocks []eetypes.CompositeBlock
+	for i := uint64(0); i < 5; i++ {
+		block := eetypes.NewCompositeBlock(common.BigToHash(new(big.Int).SetUint64(i+1)), common.BigToHash(new(big.Int).SetUint64(i+100)))
+		require.NoError(t, blockStore.SaveWithNumber(block, i))
+		blocks = append(blocks, block)
+	}
+	unnumbered := eetypes.NewCompositeBlock(common.HexToHash("0xff"), common.Hash{})
+	require.NoError(t, blockStore.Save(unnumbered))
+	// saving the same block twice does not change the size of the store.
+	require.NoError(t, blockStore.Save(unnumbered))
+	require.Equal(t, 7, blockStore.Size())
+
+	pruned, err := blockStore.PruneBelow(3)
+	require.NoError(t, err)
+	require.Equal(t, 4, pruned)
+	require.Equal(t, 3, blockStore.Size())
+
+	_, err = blockStore.Get(early.Hash())
+	require.ErrorIs(t, err, store.ErrNotFound)
+	_, err = blockStore.GetByGethHash(early.GethHash)
+	require.ErrorIs(t, err, store.ErrNotFound)
+
+	for i, block := range blocks {
+		_, err := blockStore.Get(block.Hash())
+		_, gethErr := blockStore.GetByGethHash(block.GethHash)
+		_, numberErr := blockStore.GetByNumber(uint64(i))
+		if i < 3 {
+			require.ErrorIs(t, err, store.ErrNotFound)
+			require.ErrorIs(t, gethErr, store.ErrNotFound)
+			require.ErrorIs(t, numberErr, store.ErrNotFound)
+		} else {
+			require.NoError(t, err)
+			require.NoError(t, gethErr)
+			require.NoError(t, numberErr)
+		}
+	}
+
+	number, err := blockStore.NumberOf(blocks[4].Hash())
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), number)
+
+	_, err = blockStore.Get(unnumbered.Hash())
+	require.NoError(t, err)
+
+	pruned, err = blockStore.PruneBelow(5)
+	require.NoError(t, err)
+	require.Equal(t, 3, pruned)
+	require.Zero(t, blockStore.Size())
+
+	_, err = blockStore.Get(unnumbered.Hash())
+	require.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func TestBlockStoreReplaceNumber(t *testing.T) {
+	blockStore, err := store.OpenBlockStore("", "", store.NopMetrics())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, blockStore.Close()) })
+
+	// a block saved without its height first is moved to its height once known.
+	parent := eetypes.NewCompositeBlock(common.HexToHash("0x01"), common.HexToHash("0x11"))
+	require.NoError(t, blockStore.Save(parent))
+	require.NoError(t, blockStore.SaveWithNumber(parent, 6))
+
+	original := eetypes.NewCompositeBlock(common.HexToHash("0x02"), common.HexToHash("0x12"))
+	require.NoError(t, blockStore.SaveWithNumber(original, 7))
+	require.Equal(t, 2, blockStore.Size())
+
+	// a reorged block at the same height takes over the height index, the original one is kept.
+	reorged := eetypes.NewCompositeBlock(common.HexToHash("0x03"), common.HexToHash("0x12"))
+	require.NoError(t, blockStore.SaveWithNumber(reorged, 7))
+	require.Equal(t, 3, blockStore.Size())
+
+	got, err := blockStore.GetByNumber(7)
+	require.NoError(t, err)
+	require.Equal(t, reorged, got)
+	got, err = blockStore.GetByABCIHash(reorged.ABCIHash)
+	require.NoError(t, err)
+	require.Equal(t, reorged, got)
+
+	got, err = blockStore.Get(original.Hash())
+	require.NoError(t, err)
+	require.Equal(t, original, got)
+	got, err = blockStore.GetByGethHash(original.GethHash)
+	require.NoError(t, err)
+	require.Equal(t, original, got)
+	number, err := blockStore.NumberOf(original.Hash())
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), number)
+
+	pruned, err := blockStore.PruneBelow(7)
+	require.NoError(t, err)
+	require.Equal(t, 1, pruned)
+	_, err = blockStore.Get(parent.Hash())
+	require.ErrorIs(t, err, store.ErrNotFound)
+
+	// both blocks at the height are pruned with it.
+	pruned, err = blockStore.PruneBelow(8)
+	require.NoError(t, err)
+	require.Equal(t, 2, pruned)
+	require.Zero(t, blockStore.Size())
+	_, err = blockStore.GetByNumber(7)
+	require.ErrorIs(t, err, store.ErrNotFound)
+	_, err = blockStore.GetByABCIHash(reorged.ABCIHash)
+	require.ErrorIs(t, err, store.ErrNotFound)
+}
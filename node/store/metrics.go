@@ -0,0 +1,63 @@
+package store
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsSubsystem is the subsystem shared by all metrics exposed by this package.
+const MetricsSubsystem = "store"
+
+// Metrics contains the metrics exposed by the stores.
+type Metrics struct {
+	// Number of composite blocks held in the block store.
+	CompositeBlocks metrics.Gauge
+	// Number of composite blocks removed by pruning.
+	PrunedBlocks metrics.Counter
+	// Number of composite payloads held in the payload store.
+	CompositePayloads metrics.Gauge
+	// Number of composite payloads removed because they were never served.
+	ExpiredPayloads metrics.Counter
+}
+
+// PrometheusMetrics returns Metrics registered with the default prometheus registry.
+func PrometheusMetrics(namespace string) *Metrics {
+	return &Metrics{
+		CompositeBlocks: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "composite_blocks",
+			Help:      "Number of composite blocks held in the block store.",
+		}, nil),
+		PrunedBlocks: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "pruned_blocks",
+			Help:      "Number of composite blocks removed by pruning.",
+		}, nil),
+		CompositePayloads: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "composite_payloads",
+			Help:      "Number of composite payloads held in the payload store.",
+		}, nil),
+		ExpiredPayloads: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "expired_payloads",
+			Help:      "Number of composite payloads removed because they were never served.",
+		}, nil),
+	}
+}
+
+// NopMetrics returns Metrics that discard all observations.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		CompositeBlocks:   discard.NewGauge(),
+		PrunedBlocks:      discard.NewCounter(),
+		CompositePayloads: discard.NewGauge(),
+		ExpiredPayloads:   discard.NewCounter(),
+	}
+}
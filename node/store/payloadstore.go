@@ -0,0 +1,92 @@
+package store
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+
+	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
+)
+
+// DefaultPayloadTTL is how long a composite payload is kept if it is never served.
+const DefaultPayloadTTL = time.Minute
+
+// payloadEntry is a composite payload along with the time it was saved at.
+type payloadEntry struct {
+	payload eetypes.CompositePayload
+	savedAt time.Time
+}
+
+// PayloadStore keeps composite payloads in memory until they are served or expire. Payload ids
+// only live for the duration of a block building job so they are not persisted.
+type PayloadStore struct {
+	ttl     time.Duration
+	metrics *Metrics
+
+	mtx      sync.Mutex
+	payloads map[eth.PayloadID]payloadEntry
+}
+
+// NewPayloadStore returns a PayloadStore expiring payloads after ttl.
+func NewPayloadStore(ttl time.Duration, metrics *Metrics) *PayloadStore {
+	if ttl <= 0 {
+		ttl = DefaultPayloadTTL
+	}
+
+	return &PayloadStore{
+		ttl:      ttl,
+		metrics:  metrics,
+		payloads: make(map[eth.PayloadID]payloadEntry),
+	}
+}
+
+// Get returns the composite payload with the given id or ErrNotFound.
+func (s *PayloadStore) Get(id eth.PayloadID) (eetypes.CompositePayload, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	entry, ok := s.payloads[id]
+	if !ok || time.Since(entry.savedAt) > s.ttl {
+		return eetypes.CompositePayload{}, ErrNotFound
+	}
+
+	return entry.payload, nil
+}
+
+// Save stores the composite payload under its combined id and expires stale payloads.
+func (s *PayloadStore) Save(payload eetypes.CompositePayload) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.expire()
+	s.payloads[*payload.Payload()] = payloadEntry{payload: payload, savedAt: time.Now()}
+	s.metrics.CompositePayloads.Set(float64(len(s.payloads)))
+}
+
+// Delete removes the composite payload with the given id, done once it has been served.
+func (s *PayloadStore) Delete(id eth.PayloadID) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	delete(s.payloads, id)
+	s.metrics.CompositePayloads.Set(float64(len(s.payloads)))
+}
+
+// Size returns the number of composite payloads in the store.
+func (s *PayloadStore) Size() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return len(s.payloads)
+}
+
+// expire removes all payloads older than the ttl. Must be called with mtx held.
+func (s *PayloadStore) expire() {
+	for id, entry := range s.payloads {
+		if time.Since(entry.savedAt) > s.ttl {
+			delete(s.payloads, id)
+			s.metrics.ExpiredPayloads.Add(1)
+		}
+	}
+}
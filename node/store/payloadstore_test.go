@@ -0,0 +1,46 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+
+	"github.com/ibc-scouts/ibc-interceptor/node/store"
+	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
+)
+
+func TestPayloadStore(t *testing.T) {
+	payloadStore := store.NewPayloadStore(time.Hour, store.NopMetrics())
+
+	payload := eetypes.NewCompositePayload(&eth.PayloadID{1}, &eth.PayloadID{2})
+	payloadStore.Save(payload)
+	require.Equal(t, 1, payloadStore.Size())
+
+	got, err := payloadStore.Get(*payload.Payload())
+	require.NoError(t, err)
+	require.Equal(t, payload, got)
+
+	// served payloads are deleted.
+	payloadStore.Delete(*payload.Payload())
+	_, err = payloadStore.Get(*payload.Payload())
+	require.ErrorIs(t, err, store.ErrNotFound)
+	require.Equal(t, 0, payloadStore.Size())
+}
+
+func TestPayloadStoreExpiry(t *testing.T) {
+	payloadStore := store.NewPayloadStore(time.Millisecond, store.NopMetrics())
+
+	stale := eetypes.NewCompositePayload(&eth.PayloadID{1}, &eth.PayloadID{2})
+	payloadStore.Save(stale)
+	time.Sleep(5 * time.Millisecond)
+
+	_, err := payloadStore.Get(*stale.Payload())
+	require.ErrorIs(t, err, store.ErrNotFound)
+
+	// saving a new payload evicts the stale one.
+	payloadStore.Save(eetypes.NewCompositePayload(&eth.PayloadID{3}, &eth.PayloadID{4}))
+	require.Equal(t, 1, payloadStore.Size())
+}
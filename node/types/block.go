@@ -2,26 +2,139 @@ package types
 
 import (
 	"crypto/sha256"
+	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
+const (
+	// compositeBlockLength is the length of a marshalled legacy composite block: both hashes back
+	// to back.
+	compositeBlockLength = 2 * common.HashLength
+	// versionedCompositeBlockLength is the length of a marshalled composite block using a
+	// versioned hash scheme: the scheme followed by both hashes.
+	versionedCompositeBlockLength = 1 + compositeBlockLength
+
+	// compositeHashDomain separates the preimages of versioned composite hashes from other hashes
+	// of the same block hashes.
+	compositeHashDomain = "ibc-interceptor/composite-block"
+)
+
+// HashScheme selects how the hash of a composite block is computed. Versioned schemes hash the
+// domain separator and the scheme, as version byte, ahead of the block hashes.
+type HashScheme byte
+
+const (
+	// HashSchemeLegacy is sha256(gethHash || abciHash), without domain separation.
+	HashSchemeLegacy HashScheme = iota
+	// HashSchemeSHA256V1 is sha256(domain || 0x01 || gethHash || abciHash).
+	HashSchemeSHA256V1
+	// HashSchemeKeccak256V1 is keccak256(domain || 0x02 || gethHash || abciHash), the hash
+	// function used by Ethereum tooling.
+	HashSchemeKeccak256V1
+)
+
+// hashSchemeNames are the names of the hash schemes in the configuration.
+var hashSchemeNames = map[HashScheme]string{
+	HashSchemeLegacy:      "legacy",
+	HashSchemeSHA256V1:    "sha256-v1",
+	HashSchemeKeccak256V1: "keccak256-v1",
+}
+
+// ParseHashScheme returns the hash scheme with the given name, the legacy one if empty.
+func ParseHashScheme(name string) (HashScheme, error) {
+	if name == "" {
+		return HashSchemeLegacy, nil
+	}
+	for scheme, schemeName := range hashSchemeNames {
+		if schemeName == name {
+			return scheme, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown composite hash scheme %q", name)
+}
+
+func (s HashScheme) String() string {
+	if name, ok := hashSchemeNames[s]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("HashScheme(%d)", byte(s))
+}
+
+// CompositeBlock pairs a geth block with the abci block built along with it. Each composite block
+// keeps the hash scheme it was created with, so that blocks stored under a previous scheme keep
+// their hash after the configured one changes.
 type CompositeBlock struct {
 	GethHash common.Hash
 	ABCIHash common.Hash
+	Scheme   HashScheme
 }
 
+// NewCompositeBlock returns a composite block hashed with the legacy scheme.
 func NewCompositeBlock(gethHash, abciHash common.Hash) CompositeBlock {
+	return NewCompositeBlockWithScheme(gethHash, abciHash, HashSchemeLegacy)
+}
+
+// NewCompositeBlockWithScheme returns a composite block hashed with the given scheme.
+func NewCompositeBlockWithScheme(gethHash, abciHash common.Hash, scheme HashScheme) CompositeBlock {
 	return CompositeBlock{
 		GethHash: gethHash,
 		ABCIHash: abciHash,
+		Scheme:   scheme,
 	}
 }
 
 func (b CompositeBlock) Hash() common.Hash {
-	buf := b.GethHash.Bytes()
+	if b.Scheme == HashSchemeLegacy {
+		buf := b.GethHash.Bytes()
+		buf = append(buf, b.ABCIHash.Bytes()...)
+
+		hash := sha256.Sum256(buf)
+		return common.BytesToHash(hash[:])
+	}
+
+	buf := make([]byte, 0, len(compositeHashDomain)+versionedCompositeBlockLength)
+	buf = append(buf, compositeHashDomain...)
+	buf = append(buf, byte(b.Scheme))
+	buf = append(buf, b.GethHash.Bytes()...)
 	buf = append(buf, b.ABCIHash.Bytes()...)
 
+	if b.Scheme == HashSchemeKeccak256V1 {
+		return crypto.Keccak256Hash(buf)
+	}
 	hash := sha256.Sum256(buf)
 	return common.BytesToHash(hash[:])
 }
+
+// Marshal encodes the composite block as the geth hash followed by the abci hash. Blocks using a
+// versioned hash scheme are prefixed with it, legacy ones keep the original encoding.
+func (b CompositeBlock) Marshal() []byte {
+	bz := make([]byte, 0, versionedCompositeBlockLength)
+	if b.Scheme != HashSchemeLegacy {
+		bz = append(bz, byte(b.Scheme))
+	}
+	bz = append(bz, b.GethHash.Bytes()...)
+	return append(bz, b.ABCIHash.Bytes()...)
+}
+
+// UnmarshalCompositeBlock decodes a composite block previously encoded with Marshal.
+func UnmarshalCompositeBlock(bz []byte) (CompositeBlock, error) {
+	scheme := HashSchemeLegacy
+	switch len(bz) {
+	case compositeBlockLength:
+	case versionedCompositeBlockLength:
+		scheme, bz = HashScheme(bz[0]), bz[1:]
+		if _, ok := hashSchemeNames[scheme]; !ok || scheme == HashSchemeLegacy {
+			return CompositeBlock{}, fmt.Errorf("invalid composite block hash scheme %d", byte(scheme))
+		}
+	default:
+		return CompositeBlock{}, fmt.Errorf(
+			"invalid composite block length: expected %d or %d, got %d", compositeBlockLength, versionedCompositeBlockLength, len(bz),
+		)
+	}
+
+	return NewCompositeBlockWithScheme(common.BytesToHash(bz[:common.HashLength]), common.BytesToHash(bz[common.HashLength:]), scheme), nil
+}
@@ -0,0 +1,92 @@
+package types
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestCompositeBlockHash(t *testing.T) {
+	gethHash, abciHash := common.HexToHash("0x01"), common.HexToHash("0x02")
+	preimage := func(version byte) []byte {
+		return append(append(append([]byte(compositeHashDomain), version), gethHash.Bytes()...), abciHash.Bytes()...)
+	}
+	sha256Hash := func(bz []byte) common.Hash {
+		hash := sha256.Sum256(bz)
+		return hash
+	}
+
+	testCases := []struct {
+		name    string
+		scheme  HashScheme
+		expHash common.Hash
+	}{
+		{"legacy", HashSchemeLegacy, sha256Hash(append(gethHash.Bytes(), abciHash.Bytes()...))},
+		{"sha256 v1", HashSchemeSHA256V1, sha256Hash(preimage(1))},
+		{"keccak256 v1", HashSchemeKeccak256V1, crypto.Keccak256Hash(preimage(2))},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			block := NewCompositeBlockWithScheme(gethHash, abciHash, tc.scheme)
+			require.Equal(t, tc.expHash, block.Hash())
+
+			// The scheme survives encoding, so stored blocks keep their hash.
+			decoded, err := UnmarshalCompositeBlock(block.Marshal())
+			require.NoError(t, err)
+			require.Equal(t, block, decoded)
+			require.Equal(t, tc.expHash, decoded.Hash())
+		})
+	}
+}
+
+func TestUnmarshalCompositeBlock(t *testing.T) {
+	gethHash, abciHash := common.HexToHash("0x01"), common.HexToHash("0x02")
+	legacy := append(gethHash.Bytes(), abciHash.Bytes()...)
+
+	testCases := []struct {
+		name     string
+		bz       []byte
+		expBlock CompositeBlock
+		expErr   bool
+	}{
+		{"legacy encoding", legacy, NewCompositeBlock(gethHash, abciHash), false},
+		{"versioned encoding", append([]byte{2}, legacy...), NewCompositeBlockWithScheme(gethHash, abciHash, HashSchemeKeccak256V1), false},
+		{"legacy scheme in versioned encoding", append([]byte{0}, legacy...), CompositeBlock{}, true},
+		{"unknown scheme", append([]byte{9}, legacy...), CompositeBlock{}, true},
+		{"invalid length", legacy[1:], CompositeBlock{}, true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			block, err := UnmarshalCompositeBlock(tc.bz)
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expBlock, block)
+		})
+	}
+}
+
+func TestParseHashScheme(t *testing.T) {
+	for _, scheme := range []HashScheme{HashSchemeLegacy, HashSchemeSHA256V1, HashSchemeKeccak256V1} {
+		parsed, err := ParseHashScheme(scheme.String())
+		require.NoError(t, err)
+		require.Equal(t, scheme, parsed)
+	}
+
+	parsed, err := ParseHashScheme("")
+	require.NoError(t, err)
+	require.Equal(t, HashSchemeLegacy, parsed)
+
+	_, err = ParseHashScheme("sha3")
+	require.Error(t, err)
+}
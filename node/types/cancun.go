@@ -0,0 +1,39 @@
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+)
+
+// The op-service eth types predate Ecotone (Cancun), the types below extend them with the fields
+// added by the V3 engine API methods. All new fields are omitted when empty so the types can
+// be used for the V1 and V2 methods as well.
+
+// PayloadAttributesV3 is eth.PayloadAttributes with the Ecotone fields.
+type PayloadAttributesV3 struct {
+	eth.PayloadAttributes
+	// ParentBeaconBlockRoot is the root of the parent beacon block, nil pre-Ecotone.
+	ParentBeaconBlockRoot *common.Hash `json:"parentBeaconBlockRoot,omitempty"`
+}
+
+// ExecutionPayloadV3 is eth.ExecutionPayload with the Ecotone fields.
+type ExecutionPayloadV3 struct {
+	eth.ExecutionPayload
+	// nil if not present, pre-Ecotone
+	BlobGasUsed *eth.Uint64Quantity `json:"blobGasUsed,omitempty"`
+	// nil if not present, pre-Ecotone
+	ExcessBlobGas *eth.Uint64Quantity `json:"excessBlobGas,omitempty"`
+}
+
+// ExecutionPayloadEnvelopeV3 is the result of engine_getPayloadV3.
+type ExecutionPayloadEnvelopeV3 struct {
+	ExecutionPayload      *ExecutionPayloadV3   `json:"executionPayload"`
+	BlockValue            *hexutil.Big          `json:"blockValue,omitempty"`
+	BlobsBundle           *engine.BlobsBundleV1 `json:"blobsBundle,omitempty"`
+	ShouldOverrideBuilder bool                  `json:"shouldOverrideBuilder,omitempty"`
+	// ParentBeaconBlockRoot is the root the payload was built on, nil pre-Ecotone.
+	ParentBeaconBlockRoot *common.Hash `json:"parentBeaconBlockRoot,omitempty"`
+}
@@ -0,0 +1,51 @@
+package types_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+
+	eetypes "github.com/ibc-scouts/ibc-interceptor/node/types"
+)
+
+func TestPayloadAttributesV3JSON(t *testing.T) {
+	attrs := eth.PayloadAttributes{Timestamp: 10, NoTxPool: true}
+
+	// without the Ecotone fields the encoding matches the V2 attributes.
+	v2, err := json.Marshal(attrs)
+	require.NoError(t, err)
+	v3, err := json.Marshal(eetypes.PayloadAttributesV3{PayloadAttributes: attrs})
+	require.NoError(t, err)
+	require.JSONEq(t, string(v2), string(v3))
+
+	root := common.HexToHash("0x01")
+	v3, err = json.Marshal(eetypes.PayloadAttributesV3{PayloadAttributes: attrs, ParentBeaconBlockRoot: &root})
+	require.NoError(t, err)
+
+	var decoded eetypes.PayloadAttributesV3
+	require.NoError(t, json.Unmarshal(v3, &decoded))
+	require.Equal(t, attrs.Timestamp, decoded.Timestamp)
+	require.Equal(t, root, *decoded.ParentBeaconBlockRoot)
+}
+
+func TestExecutionPayloadV3JSON(t *testing.T) {
+	blobGasUsed := eth.Uint64Quantity(3)
+	payload := eetypes.ExecutionPayloadV3{
+		ExecutionPayload: eth.ExecutionPayload{BlockNumber: 2, BlockHash: common.HexToHash("0x02")},
+		BlobGasUsed:      &blobGasUsed,
+	}
+
+	bz, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(bz, &fields))
+	require.Equal(t, "0x2", fields["blockNumber"])
+	require.Equal(t, "0x3", fields["blobGasUsed"])
+	require.NotContains(t, fields, "excessBlobGas")
+}
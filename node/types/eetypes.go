@@ -19,9 +19,10 @@ type (
 )
 
 var (
-	emptyPayloadID = PayloadID{}
-	UnknownPayload = engine.UnknownPayload
-	ZeroHash       = Hash{}
+	emptyPayloadID         = PayloadID{}
+	UnknownPayload         = engine.UnknownPayload
+	InvalidForkChoiceState = engine.InvalidForkChoiceState
+	ZeroHash               = Hash{}
 )
 
 // Verifies HeadBlockHash is empty
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"crypto/sha256"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// MsgEnvelope is a cosmos message queued in the interceptor mempool and forwarded to peptide. The
+// message is wrapped in an Any so peptide can decode it by its type url.
+type MsgEnvelope struct {
+	// ID is the sha256 hash of the marshalled Any followed by the source if set, identical
+	// messages of the same origin share their id.
+	ID      common.Hash   `json:"id"`
+	TypeURL string        `json:"typeUrl"`
+	Value   hexutil.Bytes `json:"value"`
+	// Sender is the bech32 address of the first signer of the message.
+	Sender      string    `json:"sender"`
+	ArrivalTime time.Time `json:"arrivalTime"`
+	// Source is the hash of the L2 transaction the message originates from, zero for messages
+	// submitted directly. Distinct transactions making identical bridge calls yield distinct
+	// envelopes.
+	Source common.Hash `json:"source,omitempty"`
+}
+
+// NewMsgEnvelope wraps msg into an envelope arriving at the given time. msg must have passed
+// ValidateBasic, its signers are assumed to be valid addresses.
+func NewMsgEnvelope(msg sdk.Msg, arrivalTime time.Time) (*MsgEnvelope, error) {
+	return NewSourcedMsgEnvelope(msg, common.Hash{}, arrivalTime)
+}
+
+// NewSourcedMsgEnvelope is like NewMsgEnvelope for a message originating from the L2
+// transaction with the given hash.
+func NewSourcedMsgEnvelope(msg sdk.Msg, source common.Hash, arrivalTime time.Time) (*MsgEnvelope, error) {
+	msgAny, err := codectypes.NewAnyWithValue(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	anyBz, err := msgAny.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	var sender string
+	if signers := msg.GetSigners(); len(signers) > 0 {
+		sender = signers[0].String()
+	}
+
+	if source != (common.Hash{}) {
+		anyBz = append(anyBz, source.Bytes()...)
+	}
+
+	return &MsgEnvelope{
+		ID:          sha256.Sum256(anyBz),
+		TypeURL:     msgAny.TypeUrl,
+		Value:       msgAny.Value,
+		Sender:      sender,
+		ArrivalTime: arrivalTime,
+		Source:      source,
+	}, nil
+}
+
+// Any returns the message wrapped in an Any.
+func (e *MsgEnvelope) Any() *codectypes.Any {
+	return &codectypes.Any{TypeUrl: e.TypeURL, Value: e.Value}
+}
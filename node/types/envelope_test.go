@@ -0,0 +1,51 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+)
+
+func TestNewMsgEnvelope(t *testing.T) {
+	signer := sdk.AccAddress(make([]byte, 20)).String()
+	msg := channeltypes.NewMsgChannelOpenConfirm("transfer", "channel-0", []byte("proof"), clienttypes.NewHeight(0, 1), signer)
+
+	envelope, err := NewMsgEnvelope(msg, time.Unix(1, 0))
+	require.NoError(t, err)
+	require.Equal(t, "/ibc.core.channel.v1.MsgChannelOpenConfirm", envelope.TypeURL)
+	require.Equal(t, signer, envelope.Sender)
+	require.Equal(t, time.Unix(1, 0), envelope.ArrivalTime)
+
+	var decoded channeltypes.MsgChannelOpenConfirm
+	require.NoError(t, decoded.Unmarshal(envelope.Any().Value))
+	require.Equal(t, *msg, decoded)
+
+	// The id only depends on the message.
+	later, err := NewMsgEnvelope(msg, time.Unix(2, 0))
+	require.NoError(t, err)
+	require.Equal(t, envelope.ID, later.ID)
+
+	other, err := NewMsgEnvelope(channeltypes.NewMsgChannelOpenConfirm("transfer", "channel-1", []byte("proof"), clienttypes.NewHeight(0, 1), signer), time.Unix(1, 0))
+	require.NoError(t, err)
+	require.NotEqual(t, envelope.ID, other.ID)
+
+	// Identical messages of distinct transactions have distinct ids.
+	sourced, err := NewSourcedMsgEnvelope(msg, common.HexToHash("0x01"), time.Unix(1, 0))
+	require.NoError(t, err)
+	require.Equal(t, common.HexToHash("0x01"), sourced.Source)
+	require.NotEqual(t, envelope.ID, sourced.ID)
+	otherSource, err := NewSourcedMsgEnvelope(msg, common.HexToHash("0x02"), time.Unix(1, 0))
+	require.NoError(t, err)
+	require.NotEqual(t, sourced.ID, otherSource.ID)
+	sameSource, err := NewSourcedMsgEnvelope(msg, common.HexToHash("0x01"), time.Unix(2, 0))
+	require.NoError(t, err)
+	require.Equal(t, sourced.ID, sameSource.ID)
+}
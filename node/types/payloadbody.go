@@ -0,0 +1,30 @@
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// CompositePayloadBody is the body of a composite block as returned by the
+// engine_getPayloadBodiesBy*V1 methods: the geth payload body extended with the cosmos
+// transactions of the abci block.
+type CompositePayloadBody struct {
+	engine.ExecutionPayloadBodyV1
+	// CosmosTransactions are the raw cosmos transactions of the abci block.
+	CosmosTransactions []hexutil.Bytes `json:"cosmosTransactions,omitempty"`
+}
+
+// NewCompositePayloadBody combines the geth and abci payload bodies of a composite block. Returns
+// nil if the geth body is nil, i.e. the block is unknown. abciBody may be nil.
+func NewCompositePayloadBody(gethBody, abciBody *engine.ExecutionPayloadBodyV1) *CompositePayloadBody {
+	if gethBody == nil {
+		return nil
+	}
+
+	body := &CompositePayloadBody{ExecutionPayloadBodyV1: *gethBody}
+	if abciBody != nil {
+		body.CosmosTransactions = abciBody.TransactionData
+	}
+
+	return body
+}
@@ -3,6 +3,8 @@ package types
 import (
 	"crypto/sha256"
 
+	"github.com/ethereum/go-ethereum/common"
+
 	"github.com/ethereum-optimism/optimism/op-service/eth"
 )
 
@@ -12,6 +14,10 @@ type CompositePayload struct {
 	// NOTE!: Both payloads may be nil.
 	GethPayload *eth.PayloadID
 	ABCIPayload *eth.PayloadID
+
+	// ParentBeaconBlockRoot is the root the payloads are built on, nil pre-Ecotone. Not part
+	// of the combined payload id.
+	ParentBeaconBlockRoot *common.Hash
 }
 
 func NewCompositePayload(gethPayload, abciPayload *eth.PayloadID) CompositePayload {
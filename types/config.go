@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 )
 
 const DefaultConfigFilePath = "config.json"
@@ -18,6 +19,68 @@ type Config struct {
 
 	EngineServerAddr  string `json:"engineServerAddr"`
 	PeptideEngineAddr string `json:"peptideEngineAddr"`
+
+	// DataDir is the directory the interceptor persists its stores in. If empty, in-memory
+	// stores are used and all state is lost on restart.
+	DataDir string `json:"dataDir"`
+	// DBBackend is the cometbft-db backend used for the stores in DataDir, defaults to "goleveldb".
+	DBBackend string `json:"dbBackend"`
+
+	// RetainBlocks is the number of composite blocks kept below the finalized head, older ones
+	// are pruned. Zero disables pruning.
+	RetainBlocks uint64 `json:"retainBlocks"`
+	// CompositeHashScheme is the scheme composite block hashes are computed with: "legacy",
+	// "sha256-v1" or "keccak256-v1", defaults to "legacy". Blocks stored under another scheme
+	// keep their hash, so the scheme can be changed on an existing data directory.
+	CompositeHashScheme string `json:"compositeHashScheme"`
+	// PayloadTTL is how long a payload id is kept if it is never retrieved, e.g. "1m".
+	PayloadTTL Duration `json:"payloadTTL"`
+
+	// MetricsAddr is the address the prometheus metrics are served on, disabled if empty.
+	MetricsAddr string `json:"metricsAddr"`
+
+	// MempoolMaxMsgs and MempoolMaxBytes bound the number and total size of the cosmos messages
+	// waiting to be forwarded to peptide, zero selects the defaults.
+	MempoolMaxMsgs  int `json:"mempoolMaxMsgs"`
+	MempoolMaxBytes int `json:"mempoolMaxBytes"`
+	// MempoolTTL is how long a cosmos message waits to be forwarded before it is evicted, e.g. "10m".
+	MempoolTTL Duration `json:"mempoolTTL"`
+
+	// RPCTimeout bounds the calls forwarded to geth and peptide by a served method, e.g. "10s".
+	// Zero selects the default.
+	RPCTimeout Duration `json:"rpcTimeout"`
+	// RPCMethodTimeouts overrides RPCTimeout for individual served methods, keyed by method name
+	// such as "engine_newPayloadV3", and for the messages forwarded to peptide by fork choice
+	// updates, keyed by "intercept_addMsgToTxMempool".
+	RPCMethodTimeouts map[string]Duration `json:"rpcMethodTimeouts"`
+
+	// IBCBridgeAddresses are the hex addresses of the IBCStandardBridge contracts whose calls are
+	// turned into cosmos messages, defaults to the bridge predeploy.
+	IBCBridgeAddresses []string `json:"ibcBridgeAddresses"`
+}
+
+// Duration is a time.Duration that is encoded in JSON as a string such as "1m30s".
+type Duration time.Duration
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(bz []byte) error {
+	var s string
+	if err := json.Unmarshal(bz, &s); err != nil {
+		return fmt.Errorf("duration must be a string: %w", err)
+	}
+
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(duration)
+	return nil
 }
 
 // ConfigFromFilePath reads a Config from a file.